@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"context"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
@@ -55,6 +57,7 @@ func (a *acling) getAclList(path string) []zk.ACL {
 
 type getACLBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	stat          *zk.Stat
 }
@@ -63,7 +66,9 @@ func (b *getACLBuilder) ForPath(givenPath string) ([]zk.ACL, error) {
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, GET_ACL, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	} else {
@@ -94,32 +99,32 @@ func (b *getACLBuilder) pathInBackground(path string, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *getACLBuilder) pathInForeground(path string) ([]zk.ACL, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "getACL", Name: "getACLBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
 			acls, stat, err := conn.GetACL(path)
 
-			if stat != nil {
-				if b.stat != nil {
-					*b.stat = *stat
-				} else {
-					b.stat = stat
-				}
-			}
-
-			return acls, err
+			return &attemptResult{value: acls, stat: stat}, err
 		}
 	})
 
-	acls, _ := result.([]zk.ACL)
+	res, _ := result.(*attemptResult)
+
+	if res == nil {
+		return nil, err
+	}
+
+	res.storeStatIn(&b.stat)
+
+	acls, _ := res.value.([]zk.ACL)
 
 	return acls, err
 }
@@ -130,6 +135,12 @@ func (b *getACLBuilder) StoringStatIn(stat *zk.Stat) GetACLBuilder {
 	return b
 }
 
+func (b *getACLBuilder) WithContext(ctx context.Context) GetACLBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *getACLBuilder) InBackground() GetACLBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
@@ -156,6 +167,7 @@ func (b *getACLBuilder) InBackgroundWithCallbackAndContext(callback BackgroundCa
 
 type setACLBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	acling        acling
 	version       int32
@@ -165,7 +177,9 @@ func (b *setACLBuilder) ForPath(givenPath string) (*zk.Stat, error) {
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, SET_ACL, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	} else {
@@ -196,14 +210,14 @@ func (b *setACLBuilder) pathInBackground(path string, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *setACLBuilder) pathInForeground(path string) (*zk.Stat, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "setACL", Name: "setACLBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
@@ -228,6 +242,12 @@ func (b *setACLBuilder) WithVersion(version int32) SetACLBuilder {
 	return b
 }
 
+func (b *setACLBuilder) WithContext(ctx context.Context) SetACLBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *setACLBuilder) InBackground() SetACLBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
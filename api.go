@@ -1,6 +1,9 @@
 package curator
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
@@ -22,6 +25,16 @@ var (
 	ErrSessionMoved            = zk.ErrSessionMoved
 )
 
+var (
+	ErrTTLNotSupported = errors.New("TTL nodes are not supported by the connection")
+	ErrInvalidTTL      = errors.New("TTL must be positive and used with a TTL create mode")
+
+	ErrWatchesNotSupported = errors.New("persistent watches are not supported by the connection")
+	ErrNoWatcher           = errors.New("no such watcher")
+
+	ErrUnresolvedPath = errors.New("the parameters of path are not resolved")
+)
+
 var (
 	EventNodeCreated         = zk.EventNodeCreated
 	EventNodeDeleted         = zk.EventNodeDeleted
@@ -34,14 +47,52 @@ const AnyVersion int32 = -1
 type CreateMode int32
 
 const (
-	PERSISTENT            CreateMode = 0
-	PERSISTENT_SEQUENTIAL            = zk.FlagSequence
-	EPHEMERAL                        = zk.FlagEphemeral
-	EPHEMERAL_SEQUENTIAL             = zk.FlagEphemeral + zk.FlagSequence
+	PERSISTENT                     CreateMode = 0
+	PERSISTENT_SEQUENTIAL                     = zk.FlagSequence
+	EPHEMERAL                                 = zk.FlagEphemeral
+	EPHEMERAL_SEQUENTIAL                      = zk.FlagEphemeral + zk.FlagSequence
+	CONTAINER                                 = 4 // deleted by the server once its last child is deleted (ZooKeeper 3.5+)
+	PERSISTENT_WITH_TTL                       = 5 // deleted by the server if not modified within the TTL and has no children (ZooKeeper 3.5+)
+	PERSISTENT_SEQUENTIAL_WITH_TTL            = 6 // sequential version of PERSISTENT_WITH_TTL
 )
 
-func (m CreateMode) IsSequential() bool { return (m & zk.FlagSequence) == zk.FlagSequence }
-func (m CreateMode) IsEphemeral() bool  { return (m & zk.FlagEphemeral) == zk.FlagEphemeral }
+var createModeNames = map[CreateMode]string{
+	PERSISTENT:                     "PERSISTENT",
+	PERSISTENT_SEQUENTIAL:          "PERSISTENT_SEQUENTIAL",
+	EPHEMERAL:                      "EPHEMERAL",
+	EPHEMERAL_SEQUENTIAL:           "EPHEMERAL_SEQUENTIAL",
+	CONTAINER:                      "CONTAINER",
+	PERSISTENT_WITH_TTL:            "PERSISTENT_WITH_TTL",
+	PERSISTENT_SEQUENTIAL_WITH_TTL: "PERSISTENT_SEQUENTIAL_WITH_TTL",
+}
+
+// Parse the name of create mode, e.g. "EPHEMERAL_SEQUENTIAL"
+func ParseCreateMode(name string) (CreateMode, error) {
+	for mode, modeName := range createModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+
+	return PERSISTENT, fmt.Errorf("unknown create mode: %s", name)
+}
+
+func (m CreateMode) String() string {
+	if name, exists := createModeNames[m]; exists {
+		return name
+	}
+
+	return fmt.Sprintf("CreateMode(%d)", int32(m))
+}
+
+func (m CreateMode) IsSequential() bool {
+	return m == PERSISTENT_SEQUENTIAL || m == EPHEMERAL_SEQUENTIAL || m == PERSISTENT_SEQUENTIAL_WITH_TTL
+}
+func (m CreateMode) IsEphemeral() bool { return m == EPHEMERAL || m == EPHEMERAL_SEQUENTIAL }
+func (m CreateMode) IsContainer() bool { return m == CONTAINER }
+func (m CreateMode) IsTTL() bool {
+	return m == PERSISTENT_WITH_TTL || m == PERSISTENT_SEQUENTIAL_WITH_TTL
+}
 
 // Called when the async background operation completes
 type BackgroundCallback func(client CuratorFramework, event CuratorEvent) error
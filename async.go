@@ -0,0 +1,629 @@
+package curator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// The result of an asynchronous operation
+type AsyncResult struct {
+	Type     CuratorEventType
+	Err      error
+	Path     string
+	Name     string
+	Stat     *zk.Stat
+	Data     []byte
+	Children []string
+	ACLs     []zk.ACL
+}
+
+func newAsyncResult(event CuratorEvent) *AsyncResult {
+	return &AsyncResult{
+		Type:     event.Type(),
+		Err:      event.Err(),
+		Path:     event.Path(),
+		Name:     event.Name(),
+		Stat:     event.Stat(),
+		Data:     event.Data(),
+		Children: event.Children(),
+		ACLs:     event.ACLs(),
+	}
+}
+
+// The future result of an asynchronous operation, which could be chained with the following operations
+type AsyncStage struct {
+	done   chan struct{}
+	once   sync.Once
+	result *AsyncResult
+}
+
+func newAsyncStage() *AsyncStage {
+	return &AsyncStage{done: make(chan struct{})}
+}
+
+func (s *AsyncStage) resolve(result *AsyncResult) {
+	s.once.Do(func() {
+		s.result = result
+
+		close(s.done)
+	})
+}
+
+func (s *AsyncStage) reject(err error) {
+	s.resolve(&AsyncResult{Err: err})
+}
+
+// Used as the BackgroundCallback of the operation
+func (s *AsyncStage) callback(client CuratorFramework, event CuratorEvent) error {
+	s.resolve(newAsyncResult(event))
+
+	return nil
+}
+
+// Return a channel which will be closed once the operation completed
+func (s *AsyncStage) Done() <-chan struct{} {
+	return s.done
+}
+
+// Return a channel which will receive the result once the operation completed
+func (s *AsyncStage) Result() <-chan *AsyncResult {
+	ch := make(chan *AsyncResult, 1)
+
+	go func() {
+		<-s.done
+
+		ch <- s.result
+	}()
+
+	return ch
+}
+
+// Block until the operation completed and return the result
+func (s *AsyncStage) Get() *AsyncResult {
+	<-s.done
+
+	return s.result
+}
+
+// Block until the operation completed or the context is done
+func (s *AsyncStage) GetWithContext(ctx context.Context) (*AsyncResult, error) {
+	select {
+	case <-s.done:
+		return s.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Call the function with the result once the operation succeeded,
+// the returned stage fails with the error of the operation or the function.
+func (s *AsyncStage) Then(fn func(result *AsyncResult) error) *AsyncStage {
+	next := newAsyncStage()
+
+	go func() {
+		result := s.Get()
+
+		if result.Err == nil {
+			if err := fn(result); err != nil {
+				failed := *result
+
+				failed.Err = err
+
+				result = &failed
+			}
+		}
+
+		next.resolve(result)
+	}()
+
+	return next
+}
+
+// Start the following operation with the result once the operation succeeded,
+// the returned stage completes with the result of the following operation.
+func (s *AsyncStage) Compose(fn func(result *AsyncResult) *AsyncStage) *AsyncStage {
+	next := newAsyncStage()
+
+	go func() {
+		result := s.Get()
+
+		if result.Err == nil {
+			result = fn(result).Get()
+		}
+
+		next.resolve(result)
+	}()
+
+	return next
+}
+
+// The asynchronous facade of CuratorFramework, which returns AsyncStage instead of calling BackgroundCallback
+type AsyncCuratorFramework interface {
+	// Start a create builder
+	Create() AsyncCreateBuilder
+
+	// Start a delete builder
+	Delete() AsyncDeleteBuilder
+
+	// Start an exists builder
+	CheckExists() AsyncCheckExistsBuilder
+
+	// Start a get data builder
+	GetData() AsyncGetDataBuilder
+
+	// Start a set data builder
+	SetData() AsyncSetDataBuilder
+
+	// Start a get children builder
+	GetChildren() AsyncGetChildrenBuilder
+
+	// Start a get ACL builder
+	GetACL() AsyncGetACLBuilder
+
+	// Start a set ACL builder
+	SetACL() AsyncSetACLBuilder
+
+	// Start a sync builder
+	Sync() AsyncSyncBuilder
+}
+
+type AsyncCreateBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	ForPathWithData(path string, payload []byte) *AsyncStage
+
+	CreatingParentsIfNeeded() AsyncCreateBuilder
+
+	WithProtection() AsyncCreateBuilder
+
+	WithMode(mode CreateMode) AsyncCreateBuilder
+
+	WithTTL(ttl time.Duration) AsyncCreateBuilder
+
+	WithACL(acls ...zk.ACL) AsyncCreateBuilder
+
+	Compressed() AsyncCreateBuilder
+
+	WithContext(ctx context.Context) AsyncCreateBuilder
+}
+
+type AsyncDeleteBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	DeletingChildrenIfNeeded() AsyncDeleteBuilder
+
+	Guaranteed() AsyncDeleteBuilder
+
+	WithVersion(version int32) AsyncDeleteBuilder
+
+	WithContext(ctx context.Context) AsyncDeleteBuilder
+}
+
+type AsyncCheckExistsBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	Watched() AsyncCheckExistsBuilder
+
+	UsingWatcher(watcher Watcher) AsyncCheckExistsBuilder
+
+	WithContext(ctx context.Context) AsyncCheckExistsBuilder
+}
+
+type AsyncGetDataBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	Decompressed() AsyncGetDataBuilder
+
+	Watched() AsyncGetDataBuilder
+
+	UsingWatcher(watcher Watcher) AsyncGetDataBuilder
+
+	WithContext(ctx context.Context) AsyncGetDataBuilder
+}
+
+type AsyncSetDataBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	ForPathWithData(path string, payload []byte) *AsyncStage
+
+	WithVersion(version int32) AsyncSetDataBuilder
+
+	Compressed() AsyncSetDataBuilder
+
+	WithContext(ctx context.Context) AsyncSetDataBuilder
+}
+
+type AsyncGetChildrenBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	Watched() AsyncGetChildrenBuilder
+
+	UsingWatcher(watcher Watcher) AsyncGetChildrenBuilder
+
+	WithContext(ctx context.Context) AsyncGetChildrenBuilder
+}
+
+type AsyncGetACLBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	WithContext(ctx context.Context) AsyncGetACLBuilder
+}
+
+type AsyncSetACLBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	WithACL(acls ...zk.ACL) AsyncSetACLBuilder
+
+	WithVersion(version int32) AsyncSetACLBuilder
+
+	WithContext(ctx context.Context) AsyncSetACLBuilder
+}
+
+type AsyncSyncBuilder interface {
+	ForPath(path string) *AsyncStage
+
+	WithContext(ctx context.Context) AsyncSyncBuilder
+}
+
+type asyncCuratorFramework struct {
+	client CuratorFramework
+}
+
+func (c *asyncCuratorFramework) Create() AsyncCreateBuilder {
+	return &asyncCreateBuilder{c.client.Create()}
+}
+
+func (c *asyncCuratorFramework) Delete() AsyncDeleteBuilder {
+	return &asyncDeleteBuilder{c.client.Delete()}
+}
+
+func (c *asyncCuratorFramework) CheckExists() AsyncCheckExistsBuilder {
+	return &asyncCheckExistsBuilder{c.client.CheckExists()}
+}
+
+func (c *asyncCuratorFramework) GetData() AsyncGetDataBuilder {
+	return &asyncGetDataBuilder{c.client.GetData()}
+}
+
+func (c *asyncCuratorFramework) SetData() AsyncSetDataBuilder {
+	return &asyncSetDataBuilder{c.client.SetData()}
+}
+
+func (c *asyncCuratorFramework) GetChildren() AsyncGetChildrenBuilder {
+	return &asyncGetChildrenBuilder{c.client.GetChildren()}
+}
+
+func (c *asyncCuratorFramework) GetACL() AsyncGetACLBuilder {
+	return &asyncGetACLBuilder{c.client.GetACL()}
+}
+
+func (c *asyncCuratorFramework) SetACL() AsyncSetACLBuilder {
+	return &asyncSetACLBuilder{c.client.SetACL()}
+}
+
+func (c *asyncCuratorFramework) Sync() AsyncSyncBuilder {
+	return &asyncSyncBuilder{c.client.Sync()}
+}
+
+type asyncCreateBuilder struct {
+	builder CreateBuilder
+}
+
+func (b *asyncCreateBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncCreateBuilder) ForPathWithData(path string, payload []byte) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPathWithData(path, payload); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncCreateBuilder) CreatingParentsIfNeeded() AsyncCreateBuilder {
+	b.builder = b.builder.CreatingParentsIfNeeded()
+
+	return b
+}
+
+func (b *asyncCreateBuilder) WithProtection() AsyncCreateBuilder {
+	b.builder = b.builder.WithProtection()
+
+	return b
+}
+
+func (b *asyncCreateBuilder) WithMode(mode CreateMode) AsyncCreateBuilder {
+	b.builder = b.builder.WithMode(mode)
+
+	return b
+}
+
+func (b *asyncCreateBuilder) WithTTL(ttl time.Duration) AsyncCreateBuilder {
+	b.builder = b.builder.WithTTL(ttl)
+
+	return b
+}
+
+func (b *asyncCreateBuilder) WithACL(acls ...zk.ACL) AsyncCreateBuilder {
+	b.builder = b.builder.WithACL(acls...)
+
+	return b
+}
+
+func (b *asyncCreateBuilder) Compressed() AsyncCreateBuilder {
+	b.builder = b.builder.Compressed()
+
+	return b
+}
+
+func (b *asyncCreateBuilder) WithContext(ctx context.Context) AsyncCreateBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncDeleteBuilder struct {
+	builder DeleteBuilder
+}
+
+func (b *asyncDeleteBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncDeleteBuilder) DeletingChildrenIfNeeded() AsyncDeleteBuilder {
+	b.builder = b.builder.DeletingChildrenIfNeeded()
+
+	return b
+}
+
+func (b *asyncDeleteBuilder) Guaranteed() AsyncDeleteBuilder {
+	b.builder = b.builder.Guaranteed()
+
+	return b
+}
+
+func (b *asyncDeleteBuilder) WithVersion(version int32) AsyncDeleteBuilder {
+	b.builder = b.builder.WithVersion(version)
+
+	return b
+}
+
+func (b *asyncDeleteBuilder) WithContext(ctx context.Context) AsyncDeleteBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncCheckExistsBuilder struct {
+	builder CheckExistsBuilder
+}
+
+func (b *asyncCheckExistsBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncCheckExistsBuilder) Watched() AsyncCheckExistsBuilder {
+	b.builder = b.builder.Watched()
+
+	return b
+}
+
+func (b *asyncCheckExistsBuilder) UsingWatcher(watcher Watcher) AsyncCheckExistsBuilder {
+	b.builder = b.builder.UsingWatcher(watcher)
+
+	return b
+}
+
+func (b *asyncCheckExistsBuilder) WithContext(ctx context.Context) AsyncCheckExistsBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncGetDataBuilder struct {
+	builder GetDataBuilder
+}
+
+func (b *asyncGetDataBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncGetDataBuilder) Decompressed() AsyncGetDataBuilder {
+	b.builder = b.builder.Decompressed()
+
+	return b
+}
+
+func (b *asyncGetDataBuilder) Watched() AsyncGetDataBuilder {
+	b.builder = b.builder.Watched()
+
+	return b
+}
+
+func (b *asyncGetDataBuilder) UsingWatcher(watcher Watcher) AsyncGetDataBuilder {
+	b.builder = b.builder.UsingWatcher(watcher)
+
+	return b
+}
+
+func (b *asyncGetDataBuilder) WithContext(ctx context.Context) AsyncGetDataBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncSetDataBuilder struct {
+	builder SetDataBuilder
+}
+
+func (b *asyncSetDataBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncSetDataBuilder) ForPathWithData(path string, payload []byte) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPathWithData(path, payload); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncSetDataBuilder) WithVersion(version int32) AsyncSetDataBuilder {
+	b.builder = b.builder.WithVersion(version)
+
+	return b
+}
+
+func (b *asyncSetDataBuilder) Compressed() AsyncSetDataBuilder {
+	b.builder = b.builder.Compressed()
+
+	return b
+}
+
+func (b *asyncSetDataBuilder) WithContext(ctx context.Context) AsyncSetDataBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncGetChildrenBuilder struct {
+	builder GetChildrenBuilder
+}
+
+func (b *asyncGetChildrenBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncGetChildrenBuilder) Watched() AsyncGetChildrenBuilder {
+	b.builder = b.builder.Watched()
+
+	return b
+}
+
+func (b *asyncGetChildrenBuilder) UsingWatcher(watcher Watcher) AsyncGetChildrenBuilder {
+	b.builder = b.builder.UsingWatcher(watcher)
+
+	return b
+}
+
+func (b *asyncGetChildrenBuilder) WithContext(ctx context.Context) AsyncGetChildrenBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncGetACLBuilder struct {
+	builder GetACLBuilder
+}
+
+func (b *asyncGetACLBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncGetACLBuilder) WithContext(ctx context.Context) AsyncGetACLBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncSetACLBuilder struct {
+	builder SetACLBuilder
+}
+
+func (b *asyncSetACLBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncSetACLBuilder) WithACL(acls ...zk.ACL) AsyncSetACLBuilder {
+	b.builder = b.builder.WithACL(acls...)
+
+	return b
+}
+
+func (b *asyncSetACLBuilder) WithVersion(version int32) AsyncSetACLBuilder {
+	b.builder = b.builder.WithVersion(version)
+
+	return b
+}
+
+func (b *asyncSetACLBuilder) WithContext(ctx context.Context) AsyncSetACLBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
+
+type asyncSyncBuilder struct {
+	builder SyncBuilder
+}
+
+func (b *asyncSyncBuilder) ForPath(path string) *AsyncStage {
+	stage := newAsyncStage()
+
+	if _, err := b.builder.InBackgroundWithCallback(stage.callback).ForPath(path); err != nil {
+		stage.reject(err)
+	}
+
+	return stage
+}
+
+func (b *asyncSyncBuilder) WithContext(ctx context.Context) AsyncSyncBuilder {
+	b.builder = b.builder.WithContext(ctx)
+
+	return b
+}
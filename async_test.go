@@ -0,0 +1,127 @@
+package curator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AsyncCuratorFrameworkTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestAsyncCuratorFramework(t *testing.T) {
+	suite.Run(t, new(AsyncCuratorFrameworkTestSuite))
+}
+
+func (s *AsyncCuratorFrameworkTestSuite) TestGetData() {
+	s.WithNamespace("parent", func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		conn.On("Exists", "/parent").Return(true, nil, nil).Once()
+		conn.On("Get", "/parent/child").Return(data, stat, nil).Once()
+
+		select {
+		case result := <-client.Async().GetData().ForPath("/child").Result():
+			assert.Equal(s.T(), GET_DATA, result.Type)
+			assert.Equal(s.T(), "/child", result.Path)
+			assert.Equal(s.T(), "child", result.Name)
+			assert.Equal(s.T(), data, result.Data)
+			assert.Equal(s.T(), stat, result.Stat)
+			assert.NoError(s.T(), result.Err)
+		case <-time.After(time.Second):
+			s.T().Error("timeout")
+		}
+	})
+}
+
+func (s *AsyncCuratorFrameworkTestSuite) TestError() {
+	s.With(func(client CuratorFramework, conn *mockConn) {
+		conn.On("Get", "/node").Return(nil, nil, zk.ErrNoNode).Once()
+
+		var called bool
+
+		result := client.Async().GetData().ForPath("/node").Then(func(result *AsyncResult) error {
+			called = true
+
+			return nil
+		}).Get()
+
+		assert.False(s.T(), called)
+		assert.Equal(s.T(), zk.ErrNoNode, result.Err)
+		assert.Equal(s.T(), "/node", result.Path)
+	})
+}
+
+func (s *AsyncCuratorFrameworkTestSuite) TestAbandoned() {
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.BackgroundWorkers = 1
+	}, func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		started := make(chan struct{})
+		block := make(chan struct{})
+
+		conn.On("Get", "/node").Return(data, stat, nil).Run(func(args mock.Arguments) {
+			close(started)
+
+			<-block
+		}).Once()
+
+		running := client.Async().GetData().ForPath("/node")
+
+		<-started
+
+		abandoned := client.Async().GetData().ForPath("/other")
+
+		assert.Equal(s.T(), 2, client.(*curatorFramework).backgroundPool.Close(10*time.Millisecond))
+
+		// the queued operation is abandoned, the running one completes
+		result := abandoned.Get()
+
+		assert.Equal(s.T(), ErrClosing, result.Err)
+		assert.Equal(s.T(), GET_DATA, result.Type)
+		assert.Equal(s.T(), "/other", result.Path)
+
+		close(block)
+
+		assert.NoError(s.T(), running.Get().Err)
+	})
+}
+
+func (s *AsyncCuratorFrameworkTestSuite) TestCompose() {
+	s.With(func(client CuratorFramework, conn *mockConn, acls []zk.ACL, data []byte, stat *zk.Stat) {
+		events := make(chan zk.Event)
+
+		defer close(events)
+
+		conn.On("Create", "/node", data, int32(PERSISTENT), acls).Return("/node", nil).Once()
+		conn.On("ExistsW", "/node").Return(true, stat, events, nil).Once()
+
+		result := client.Async().Create().WithACL(acls...).ForPathWithData("/node", data).Compose(func(result *AsyncResult) *AsyncStage {
+			return client.Async().CheckExists().Watched().ForPath(result.Path)
+		}).Get()
+
+		assert.Equal(s.T(), EXISTS, result.Type)
+		assert.Equal(s.T(), stat, result.Stat)
+		assert.NoError(s.T(), result.Err)
+	})
+}
+
+func (s *AsyncCuratorFrameworkTestSuite) TestThen() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+
+		errInvalid := errors.New("invalid")
+
+		result := client.Async().GetData().ForPath("/node").Then(func(result *AsyncResult) error {
+			assert.Equal(s.T(), data, result.Data)
+
+			return errInvalid
+		}).Get()
+
+		assert.Equal(s.T(), data, result.Data)
+		assert.Equal(s.T(), errInvalid, result.Err)
+	})
+}
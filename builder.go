@@ -1,6 +1,9 @@
 package curator
 
 import (
+	"context"
+	"time"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
@@ -18,11 +21,35 @@ type CreateBuilder interface {
 	// Causes any parent nodes to get created if they haven't already been
 	CreatingParentsIfNeeded() CreateBuilder
 
+	// Causes any parent nodes to get created as containers if they haven't already been
+	CreatingParentContainersIfNeeded() CreateBuilder
+
+	// It protects against cases where the server creates the node,
+	// but the connection is lost before the node name is returned to the client.
+	//
+	// The node name will be prefixed with a GUID, and if a retry is needed,
+	// the parent will be searched for a node with the GUID before re-creating it.
+	WithProtection() CreateBuilder
+
+	// If the node already exists, set its data instead of failing with ErrNodeExists
+	OrSetData() CreateBuilder
+
+	// If the node already exists, set its data using the given version instead of failing with ErrNodeExists
+	OrSetDataWithVersion(version int32) CreateBuilder
+
+	// Statable[T]
+	//
+	// Have the operation fill the provided stat object
+	StoringStatIn(stat *zk.Stat) CreateBuilder
+
 	// CreateModable[T]
 	//
 	// Set a create mode - the default is CreateMode.PERSISTENT
 	WithMode(mode CreateMode) CreateBuilder
 
+	// Set the TTL of the node, which must be used with PERSISTENT_WITH_TTL or PERSISTENT_SEQUENTIAL_WITH_TTL
+	WithTTL(ttl time.Duration) CreateBuilder
+
 	// ACLable[T]
 	//
 	// Set an ACL list
@@ -33,6 +60,11 @@ type CreateBuilder interface {
 	// Cause the data to be compressed using the configured compression provider
 	Compressed() CreateBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) CreateBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -62,6 +94,11 @@ type CheckExistsBuilder interface {
 	// Set a watcher for the operation
 	UsingWatcher(watcher Watcher) CheckExistsBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) CheckExistsBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -88,11 +125,22 @@ type DeleteBuilder interface {
 	// Will also delete children if they exist.
 	DeletingChildrenIfNeeded() DeleteBuilder
 
+	// Guaranteeable[T]
+	//
+	// If the delete fails because of connection problems,
+	// it will be recorded and retried in the background until it succeeds or the node is gone.
+	Guaranteed() DeleteBuilder
+
 	// Versionable[T]
 	//
 	// Use the given version (the default is -1)
 	WithVersion(version int32) DeleteBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) DeleteBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -132,6 +180,11 @@ type GetDataBuilder interface {
 	// Set a watcher for the operation
 	UsingWatcher(watcher Watcher) GetDataBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) GetDataBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -166,6 +219,11 @@ type SetDataBuilder interface {
 	// Cause the data to be compressed using the configured compression provider
 	Compressed() SetDataBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) SetDataBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -200,6 +258,11 @@ type GetChildrenBuilder interface {
 	// Set a watcher for the operation
 	UsingWatcher(watcher Watcher) GetChildrenBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) GetChildrenBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -226,6 +289,11 @@ type GetACLBuilder interface {
 	// Have the operation fill the provided stat object
 	StoringStatIn(stat *zk.Stat) GetACLBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) GetACLBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -257,6 +325,11 @@ type SetACLBuilder interface {
 	// Use the given version (the default is -1)
 	WithVersion(version int32) SetACLBuilder
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) SetACLBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -278,6 +351,11 @@ type SyncBuilder interface {
 	// Commit the currently building operation using the given path
 	ForPath(path string) (string, error)
 
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) SyncBuilder
+
 	// Backgroundable[T]
 	//
 	// Perform the action in the background
@@ -302,11 +380,26 @@ type TransactionCreateBuilder interface {
 	// Commit the currently building operation using the given path and data
 	ForPathWithData(path string, payload []byte) TransactionBridge
 
+	// ParentsCreatable[T]
+	//
+	// Causes any missing parent nodes to get created in the same transaction
+	CreatingParentsIfNeeded() TransactionCreateBuilder
+
+	// Causes any missing parent nodes to get created as containers in the same transaction
+	CreatingParentContainersIfNeeded() TransactionCreateBuilder
+
+	// The node name will be prefixed with a GUID, which could be used to find the node created by the transaction,
+	// the commit retried after a connection loss returns the found nodes if the transaction has been committed
+	WithProtection() TransactionCreateBuilder
+
 	// CreateModable[T]
 	//
 	// Set a create mode - the default is CreateMode.PERSISTENT
 	WithMode(mode CreateMode) TransactionCreateBuilder
 
+	// Set the TTL of the node, which must be used with PERSISTENT_WITH_TTL or PERSISTENT_SEQUENTIAL_WITH_TTL
+	WithTTL(ttl time.Duration) TransactionCreateBuilder
+
 	// ACLable[T]
 	//
 	// Set an ACL list
@@ -361,3 +454,104 @@ type TransactionCheckBuilder interface {
 	// Use the given version (the default is -1)
 	WithVersion(version int32) TransactionCheckBuilder
 }
+
+type WatchesBuilder interface {
+	// Watchable[T]
+	//
+	// Set a watcher which receives the events of the persistent watch, besides CuratorListenable
+	UsingWatcher(watcher Watcher) WatchesBuilder
+
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) WatchesBuilder
+
+	// Add a persistent watch on the given path, which is not removed when triggered,
+	// and will be re-registered after the session is re-established
+	Add(path string, mode AddWatchMode) error
+
+	// Start a builder to remove the given watcher
+	Remove(watcher Watcher) RemoveWatchesBuilder
+
+	// Start a builder to remove all the watches
+	RemoveAll() RemoveWatchesBuilder
+}
+
+type RemoveWatchesBuilder interface {
+	// Pathable[T]
+	//
+	// Commit the currently building operation using the given path
+	ForPath(path string) error
+
+	// Specify the type of the watches to remove, the default is WATCHER_ANY
+	OfType(watcherType WatcherType) RemoveWatchesBuilder
+
+	// Only remove the watches locally, without contacting the server
+	Locally() RemoveWatchesBuilder
+
+	// Guaranteeable[T]
+	//
+	// If the removal fails because of connection problems,
+	// the watches will be removed locally and the removal retried on the server after reconnected.
+	Guaranteed() RemoveWatchesBuilder
+
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) RemoveWatchesBuilder
+
+	// Backgroundable[T]
+	//
+	// Perform the action in the background
+	InBackground() RemoveWatchesBuilder
+
+	// Perform the action in the background
+	InBackgroundWithContext(context interface{}) RemoveWatchesBuilder
+
+	// Perform the action in the background
+	InBackgroundWithCallback(callback BackgroundCallback) RemoveWatchesBuilder
+
+	// Perform the action in the background
+	InBackgroundWithCallbackAndContext(callback BackgroundCallback, context interface{}) RemoveWatchesBuilder
+}
+
+type UpdateBuilder interface {
+	// Read the node, update its data with the function and write it back with the read version,
+	// the whole cycle is retried if the node was changed by others.
+	ForPath(path string, update UpdateFunc) (*UpdateResult, error)
+
+	// Read the nodes, update their data with the function and commit the changes in a transaction,
+	// the nodes which are not changed will be checked for their versions in the same transaction.
+	ForPaths(paths []string, update MultiUpdateFunc) ([]UpdateResult, error)
+
+	// Create the node with the updated data if it doesn't exist, the old data will be nil
+	CreatingIfMissing() UpdateBuilder
+
+	// CreateModable[T]
+	//
+	// Set a create mode of the missing nodes - the default is CreateMode.PERSISTENT
+	WithMode(mode CreateMode) UpdateBuilder
+
+	// ACLable[T]
+	//
+	// Set an ACL list of the missing nodes
+	WithACL(acls ...zk.ACL) UpdateBuilder
+
+	// ParentsCreatable[T]
+	//
+	// Causes any parent nodes of the missing nodes to get created if they haven't already been
+	CreatingParentsIfNeeded() UpdateBuilder
+
+	// Set the policy to retry on the version conflicts, the default is the retry policy of the client
+	WithRetryPolicy(retryPolicy RetryPolicy) UpdateBuilder
+
+	// Compressible[T]
+	//
+	// Cause the data to be decompressed when reading and compressed when writing
+	Compressed() UpdateBuilder
+
+	// Contextual[T]
+	//
+	// Abort the operation when the given context is done
+	WithContext(ctx context.Context) UpdateBuilder
+}
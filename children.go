@@ -1,21 +1,32 @@
 package curator
 
 import (
+	"context"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
 type getChildrenBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	stat          *zk.Stat
 	watching      watching
 }
 
 func (b *getChildrenBuilder) ForPath(givenPath string) ([]string, error) {
+	if b.watching.watched || b.watching.watcher != nil {
+		if err := b.client.schemaSet.ValidateWatch(givenPath); err != nil {
+			return nil, err
+		}
+	}
+
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, CHILDREN, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	}
@@ -50,45 +61,46 @@ func (b *getChildrenBuilder) pathInBackground(adjustedPath, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *getChildrenBuilder) pathInForeground(path string) ([]string, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "getChildren", Name: "getChildrenBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
 			var children []string
-			var stat *zk.Stat
-			var events <-chan zk.Event
+			var res attemptResult
 			var err error
 
 			if b.watching.watched || b.watching.watcher != nil {
-				children, stat, events, err = conn.ChildrenW(path)
-
-				if events != nil && b.watching.watcher != nil {
-					go NewWatchers(b.watching.watcher).Watch(events)
-				}
+				children, res.stat, res.events, err = conn.ChildrenW(path)
 			} else {
-				children, stat, err = conn.Children(path)
+				children, res.stat, err = conn.Children(path)
 			}
 
-			if stat != nil {
-				if b.stat != nil {
-					*b.stat = *stat
-				} else {
-					b.stat = stat
-				}
-			}
+			res.value = children
 
-			return children, err
+			return &res, err
 		}
 	})
 
-	children, _ := result.([]string)
+	res, _ := result.(*attemptResult)
+
+	if res == nil {
+		return nil, err
+	}
+
+	res.storeStatIn(&b.stat)
+
+	if res.events != nil && b.watching.watcher != nil {
+		b.client.client.state.watches.watch(path, WATCHER_CHILDREN, b.watching.watcher, res.events)
+	}
+
+	children, _ := res.value.([]string)
 
 	return children, err
 }
@@ -106,7 +118,13 @@ func (b *getChildrenBuilder) Watched() GetChildrenBuilder {
 }
 
 func (b *getChildrenBuilder) UsingWatcher(watcher Watcher) GetChildrenBuilder {
-	b.watching.watcher = b.client.getNamespaceWatcher(watcher)
+	b.watching.watcher = b.client.wrapWatcher(watcher)
+
+	return b
+}
+
+func (b *getChildrenBuilder) WithContext(ctx context.Context) GetChildrenBuilder {
+	b.ctx = ctx
 
 	return b
 }
@@ -0,0 +1,384 @@
+package curator
+
+import (
+	"sync"
+	"time"
+)
+
+// The retry sleeper which records the sleep time instead of sleeping
+type capturingRetrySleeper struct {
+	sleepTime time.Duration
+}
+
+func (s *capturingRetrySleeper) SleepFor(d time.Duration) error {
+	s.sleepTime = d
+
+	return nil
+}
+
+// Circuit breaker which stays open for the backoff periods given by the retry policy
+type CircuitBreaker struct {
+	lock        sync.Mutex
+	retryPolicy RetryPolicy
+	open        bool
+	retryCount  int
+	startTime   time.Time
+	timer       *time.Timer
+	generation  int // the generation of the backoff period, a stale completion is ignored
+}
+
+func NewCircuitBreaker(retryPolicy RetryPolicy) *CircuitBreaker {
+	return &CircuitBreaker{retryPolicy: retryPolicy}
+}
+
+// Return true if the circuit is open
+func (b *CircuitBreaker) IsOpen() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.open
+}
+
+// Return the number of the backoff periods since the circuit was opened
+func (b *CircuitBreaker) RetryCount() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.retryCount
+}
+
+// Open the circuit and call the completion when the backoff period elapsed,
+// return false if the circuit is already open or the retry policy disallows it.
+func (b *CircuitBreaker) TryToOpen(completion func()) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.open {
+		return false
+	}
+
+	b.open = true
+	b.retryCount = 0
+	b.startTime = time.Now()
+
+	if b.tryToRetry(completion) {
+		return true
+	}
+
+	b.open = false
+
+	return false
+}
+
+// Extend the open circuit for another backoff period and call the completion when it elapsed,
+// return false if the circuit is not open or the retry policy disallows it.
+func (b *CircuitBreaker) TryToRetry(completion func()) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.open {
+		return false
+	}
+
+	return b.tryToRetry(completion)
+}
+
+func (b *CircuitBreaker) tryToRetry(completion func()) bool {
+	sleeper := &capturingRetrySleeper{}
+
+	if !b.retryPolicy.AllowRetry(b.retryCount, time.Since(b.startTime), sleeper) {
+		return false
+	}
+
+	b.retryCount++
+	b.generation++
+
+	generation := b.generation
+
+	b.timer = time.AfterFunc(sleeper.sleepTime, func() {
+		if b.isCurrent(generation) {
+			completion()
+		}
+	})
+
+	return true
+}
+
+func (b *CircuitBreaker) isCurrent(generation int) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.open && b.generation == generation
+}
+
+// Close the circuit, return false if it was not open
+func (b *CircuitBreaker) Close() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.open {
+		return false
+	}
+
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	b.open = false
+	b.retryCount = 0
+	b.generation++
+
+	return true
+}
+
+// The max time between the failures counted as consecutive by the CircuitBreakingRetryPolicy
+const DEFAULT_CIRCUIT_FAILURE_WINDOW = 10 * time.Second
+
+// Retry policy which opens a circuit breaker after repeated failures, or when the connection is SUSPENDED or LOST,
+// the retries are disallowed while the circuit is open so the operations fail fast instead of hammering the ensemble.
+//
+// The circuit stays open for the backoff periods of the breaker policy as long as the operations keep failing.
+// The failures are counted as consecutive until none occurs for the failure window.
+// The policy is registered as a ConnectionStateListener when it is used as the RetryPolicy of the builder,
+// otherwise it should be added to the ConnectionStateListenable of the client.
+type CircuitBreakingRetryPolicy struct {
+	RetryPolicy
+
+	breaker     *CircuitBreaker
+	threshold   int
+	window      time.Duration // the max time between the consecutive failures
+	lock        sync.Mutex
+	failures    int       // the consecutive failures since the connection was established or the circuit was closed
+	lastFailure time.Time // the time of the latest failure
+	rejected    int       // the retries rejected since the circuit was opened or extended
+}
+
+// Create a retry policy which delegates to the policy, and opens the circuit for the periods of the breaker policy
+// when the failures reach the threshold.
+func NewCircuitBreakingRetryPolicy(delegate RetryPolicy, threshold int, breakerPolicy RetryPolicy) *CircuitBreakingRetryPolicy {
+	return &CircuitBreakingRetryPolicy{
+		RetryPolicy: delegate,
+		breaker:     NewCircuitBreaker(breakerPolicy),
+		threshold:   threshold,
+		window:      DEFAULT_CIRCUIT_FAILURE_WINDOW,
+	}
+}
+
+// Set the max time between the failures counted as consecutive, 0 to count them until the connection is re-established
+func (p *CircuitBreakingRetryPolicy) WithFailureWindow(window time.Duration) *CircuitBreakingRetryPolicy {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.window = window
+
+	return p
+}
+
+// Return true if the circuit is open
+func (p *CircuitBreakingRetryPolicy) IsOpen() bool {
+	return p.breaker.IsOpen()
+}
+
+func (p *CircuitBreakingRetryPolicy) AllowRetry(retryCount int, elapsedTime time.Duration, sleeper RetrySleeper) bool {
+	p.lock.Lock()
+
+	if p.breaker.IsOpen() {
+		p.rejected++
+		p.lock.Unlock()
+
+		return false
+	}
+
+	now := time.Now()
+
+	if p.window > 0 && now.Sub(p.lastFailure) > p.window {
+		p.failures = 0 // the operations succeeded or stopped failing in the meantime
+	}
+
+	p.failures++
+	p.lastFailure = now
+
+	if p.threshold > 0 && p.failures >= p.threshold {
+		p.openCircuit()
+		p.lock.Unlock()
+
+		return false
+	}
+
+	p.lock.Unlock()
+
+	return p.RetryPolicy.AllowRetry(retryCount, elapsedTime, sleeper)
+}
+
+func (p *CircuitBreakingRetryPolicy) StateChanged(client CuratorFramework, newState ConnectionState) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch newState {
+	case SUSPENDED, LOST:
+		if p.breaker.IsOpen() {
+			p.rejected++
+		} else {
+			p.openCircuit()
+		}
+
+	case CONNECTED, RECONNECTED:
+		p.failures = 0
+	}
+}
+
+func (p *CircuitBreakingRetryPolicy) openCircuit() {
+	p.failures = 0
+	p.rejected = 0
+
+	p.breaker.TryToOpen(p.checkCloseCircuit)
+}
+
+func (p *CircuitBreakingRetryPolicy) checkCloseCircuit() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rejected := p.rejected
+
+	p.rejected = 0
+
+	if rejected > 0 && p.breaker.TryToRetry(p.checkCloseCircuit) {
+		return // still failing, keep the circuit open for another period
+	}
+
+	p.breaker.Close()
+}
+
+// Decorator of a ConnectionStateListener which opens a circuit breaker when the connection is lost,
+// the state changes are not passed to the listener while the circuit is open,
+// and a single consolidated state is delivered when the circuit closes.
+//
+// The circuit stays open for the backoff periods of the retry policy as long as the state keeps changing,
+// LOST is always delivered once, even if the circuit is open.
+type CircuitBreakingConnectionStateListener struct {
+	lock            sync.Mutex
+	listener        ConnectionStateListener
+	breaker         *CircuitBreaker
+	client          CuratorFramework
+	sentState       ConnectionState // the latest state delivered to the listener
+	pendingState    ConnectionState // the latest state received while the circuit is open
+	hasPendingState bool
+	changed         bool // the state changed during the current backoff period
+	lostHasBeenSent bool
+}
+
+// Create a listener which passes the state changes to the listener, and opens the circuit for the periods of the retry policy
+func NewCircuitBreakingConnectionStateListener(listener ConnectionStateListener, retryPolicy RetryPolicy) *CircuitBreakingConnectionStateListener {
+	return &CircuitBreakingConnectionStateListener{
+		listener: listener,
+		breaker:  NewCircuitBreaker(retryPolicy),
+	}
+}
+
+// Return true if the circuit is open
+func (l *CircuitBreakingConnectionStateListener) IsOpen() bool {
+	return l.breaker.IsOpen()
+}
+
+func (l *CircuitBreakingConnectionStateListener) StateChanged(client CuratorFramework, newState ConnectionState) {
+	var state ConnectionState
+	var send bool
+
+	l.lock.Lock()
+
+	l.client = client
+
+	if l.breaker.IsOpen() {
+		state, send = l.handleOpenStateChange(newState)
+	} else {
+		state, send = l.handleClosedStateChange(newState)
+	}
+
+	l.lock.Unlock()
+
+	// called by the connection state manager, which reports the errors and panics of the listener
+	if send {
+		l.listener.StateChanged(client, state)
+	}
+}
+
+func (l *CircuitBreakingConnectionStateListener) handleClosedStateChange(newState ConnectionState) (ConnectionState, bool) {
+	if !newState.Connected() && l.breaker.TryToOpen(l.checkCloseCircuit) {
+		l.hasPendingState = false
+		l.changed = false
+		l.lostHasBeenSent = newState == LOST
+	}
+
+	return l.sendState(newState)
+}
+
+func (l *CircuitBreakingConnectionStateListener) handleOpenStateChange(newState ConnectionState) (ConnectionState, bool) {
+	if newState == LOST && !l.lostHasBeenSent {
+		l.lostHasBeenSent = true
+		l.hasPendingState = false
+
+		return l.sendState(LOST)
+	}
+
+	l.pendingState = newState
+	l.hasPendingState = true
+	l.changed = true
+
+	return newState, false
+}
+
+// Called when the backoff period elapsed
+func (l *CircuitBreakingConnectionStateListener) checkCloseCircuit() {
+	l.lock.Lock()
+
+	if l.changed && l.breaker.TryToRetry(l.checkCloseCircuit) {
+		l.changed = false // the state is not settled, wait for another period
+
+		l.lock.Unlock()
+
+		return
+	}
+
+	client := l.client
+	state, send := l.closeCircuit()
+
+	l.lock.Unlock()
+
+	if send {
+		info := CallbackError{Source: "ConnectionStateListener", Operation: "StateChanged", EventType: state.String()}
+
+		errorHandlerOf(client).callListener(l.listener, info, func(listener interface{}) error {
+			listener.(ConnectionStateListener).StateChanged(client, state)
+
+			return nil
+		})
+	}
+}
+
+func (l *CircuitBreakingConnectionStateListener) closeCircuit() (ConnectionState, bool) {
+	state, pending := l.pendingState, l.hasPendingState && l.pendingState != l.sentState
+
+	l.hasPendingState = false
+	l.changed = false
+	l.lostHasBeenSent = false
+
+	l.breaker.Close()
+
+	if !pending {
+		return state, false
+	}
+
+	if state == CONNECTED {
+		state = RECONNECTED // the listener has seen the connection before
+	}
+
+	return l.sendState(state)
+}
+
+// Record the state to deliver to the listener after the lock is released
+func (l *CircuitBreakingConnectionStateListener) sendState(state ConnectionState) (ConnectionState, bool) {
+	l.sentState = state
+
+	return state, true
+}
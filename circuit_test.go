@@ -0,0 +1,249 @@
+package curator
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := NewCircuitBreaker(NewRetryNTimes(3, 10*time.Millisecond))
+
+	c := make(chan struct{}, 1)
+	completion := func() { c <- struct{}{} }
+
+	assert.False(t, b.IsOpen())
+	assert.False(t, b.TryToRetry(completion))
+	assert.False(t, b.Close())
+
+	assert.True(t, b.TryToOpen(completion))
+	assert.True(t, b.IsOpen())
+	assert.False(t, b.TryToOpen(completion))
+	assert.Equal(t, 1, b.RetryCount())
+
+	<-c
+
+	assert.True(t, b.TryToRetry(completion))
+	assert.Equal(t, 2, b.RetryCount())
+
+	<-c
+
+	assert.True(t, b.TryToRetry(completion))
+	assert.Equal(t, 3, b.RetryCount())
+
+	<-c
+
+	// the retry policy gives up
+	assert.False(t, b.TryToRetry(completion))
+	assert.True(t, b.IsOpen())
+	assert.True(t, b.Close())
+	assert.False(t, b.IsOpen())
+	assert.Equal(t, 0, b.RetryCount())
+
+	// the retry policy disallows to open
+	b = NewCircuitBreaker(NewRetryNTimes(0, 0))
+
+	assert.False(t, b.TryToOpen(completion))
+	assert.False(t, b.IsOpen())
+}
+
+func TestCircuitBreakingRetryPolicy(t *testing.T) {
+	delegate := &mockRetryPolicy{}
+	sleeper := &mockRetrySleeper{}
+
+	// the backoff periods are elapsed by calling checkCloseCircuit
+	p := NewCircuitBreakingRetryPolicy(delegate, 3, NewRetryNTimes(10, time.Hour))
+
+	delegate.On("AllowRetry", mock.AnythingOfType("int"), time.Duration(0), sleeper).Return(true).Twice()
+
+	assert.True(t, p.AllowRetry(1, 0, sleeper))
+	assert.True(t, p.AllowRetry(2, 0, sleeper))
+
+	// the failures reach the threshold
+	assert.False(t, p.AllowRetry(3, 0, sleeper))
+	assert.True(t, p.IsOpen())
+
+	// fail fast while the circuit is open, which extends the circuit
+	assert.False(t, p.AllowRetry(1, 0, sleeper))
+
+	p.checkCloseCircuit()
+
+	assert.True(t, p.IsOpen())
+	assert.Equal(t, 2, p.breaker.RetryCount())
+
+	// close the circuit after a period without failures
+	p.checkCloseCircuit()
+
+	assert.False(t, p.IsOpen())
+
+	delegate.AssertExpectations(t)
+
+	// the connection is suspended
+	p.StateChanged(nil, SUSPENDED)
+
+	assert.True(t, p.IsOpen())
+	assert.False(t, p.AllowRetry(1, 0, sleeper))
+
+	p.checkCloseCircuit()
+	p.checkCloseCircuit()
+
+	assert.False(t, p.IsOpen())
+
+	// the failures are reset when reconnected
+	delegate.On("AllowRetry", mock.AnythingOfType("int"), time.Duration(0), sleeper).Return(true).Times(4)
+
+	assert.True(t, p.AllowRetry(1, 0, sleeper))
+	assert.True(t, p.AllowRetry(2, 0, sleeper))
+
+	p.StateChanged(nil, RECONNECTED)
+
+	assert.True(t, p.AllowRetry(1, 0, sleeper))
+	assert.True(t, p.AllowRetry(2, 0, sleeper))
+	assert.False(t, p.IsOpen())
+
+	delegate.AssertExpectations(t)
+}
+
+func TestCircuitBreakingRetryPolicyWindow(t *testing.T) {
+	delegate := &mockRetryPolicy{}
+	sleeper := &mockRetrySleeper{}
+
+	p := NewCircuitBreakingRetryPolicy(delegate, 3, NewRetryNTimes(10, time.Hour)).WithFailureWindow(50 * time.Millisecond)
+
+	delegate.On("AllowRetry", mock.AnythingOfType("int"), time.Duration(0), sleeper).Return(true).Times(4)
+
+	assert.True(t, p.AllowRetry(0, 0, sleeper))
+	assert.True(t, p.AllowRetry(1, 0, sleeper))
+
+	// the failures are not consecutive after the window
+	time.Sleep(100 * time.Millisecond)
+
+	assert.True(t, p.AllowRetry(0, 0, sleeper))
+	assert.True(t, p.AllowRetry(1, 0, sleeper))
+	assert.False(t, p.IsOpen())
+
+	assert.False(t, p.AllowRetry(2, 0, sleeper))
+	assert.True(t, p.IsOpen())
+
+	delegate.AssertExpectations(t)
+}
+
+type recordingConnectionStateListener struct {
+	lock   sync.Mutex
+	states []ConnectionState
+}
+
+func (l *recordingConnectionStateListener) StateChanged(client CuratorFramework, newState ConnectionState) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.states = append(l.states, newState)
+}
+
+func (l *recordingConnectionStateListener) States() []ConnectionState {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]ConnectionState(nil), l.states...)
+}
+
+func TestCircuitBreakingConnectionStateListener(t *testing.T) {
+	listener := &recordingConnectionStateListener{}
+
+	// the backoff periods are elapsed by calling checkCloseCircuit
+	l := NewCircuitBreakingConnectionStateListener(listener, NewRetryNTimes(3, time.Hour))
+
+	l.StateChanged(nil, CONNECTED)
+
+	assert.False(t, l.IsOpen())
+
+	l.StateChanged(nil, SUSPENDED)
+
+	assert.True(t, l.IsOpen())
+
+	// the flaps are suppressed, but LOST is delivered once
+	l.StateChanged(nil, RECONNECTED)
+	l.StateChanged(nil, SUSPENDED)
+	l.StateChanged(nil, LOST)
+	l.StateChanged(nil, RECONNECTED)
+	l.StateChanged(nil, LOST)
+	l.StateChanged(nil, RECONNECTED)
+
+	assert.Equal(t, []ConnectionState{CONNECTED, SUSPENDED, LOST}, listener.States())
+
+	// the state changed during the period, keep the circuit open
+	l.checkCloseCircuit()
+
+	assert.True(t, l.IsOpen())
+	assert.Equal(t, []ConnectionState{CONNECTED, SUSPENDED, LOST}, listener.States())
+
+	l.checkCloseCircuit()
+
+	assert.False(t, l.IsOpen())
+
+	// the consolidated state is delivered when the circuit closes
+	assert.Equal(t, []ConnectionState{CONNECTED, SUSPENDED, LOST, RECONNECTED}, listener.States())
+
+	// nothing is delivered if the state comes back to the sent one
+	l.StateChanged(nil, SUSPENDED)
+	l.StateChanged(nil, RECONNECTED)
+	l.StateChanged(nil, SUSPENDED)
+
+	l.checkCloseCircuit()
+	l.checkCloseCircuit()
+
+	assert.False(t, l.IsOpen())
+
+	assert.Equal(t, []ConnectionState{CONNECTED, SUSPENDED, LOST, RECONNECTED, SUSPENDED}, listener.States())
+}
+
+func TestCircuitBreakingListenerGivesUp(t *testing.T) {
+	listener := &recordingConnectionStateListener{}
+
+	l := NewCircuitBreakingConnectionStateListener(listener, NewRetryOneTime(50*time.Millisecond))
+
+	l.StateChanged(nil, SUSPENDED)
+	l.StateChanged(nil, RECONNECTED)
+
+	assert.True(t, l.IsOpen())
+	assert.Equal(t, []ConnectionState{SUSPENDED}, listener.States())
+
+	// the state keeps changing, but the retry policy gives up
+	assert.Eventually(t, func() bool { return !l.IsOpen() }, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []ConnectionState{SUSPENDED, RECONNECTED}, listener.States())
+}
+
+func TestCircuitBreakingListenerPanic(t *testing.T) {
+	errs := make(chan error, 1)
+
+	listeners := &unhandledErrorListenerContainer{}
+
+	listeners.AddListener(NewUnhandledErrorListener(func(err error) {
+		errs <- err
+	}))
+
+	client := &curatorFramework{errorHandler: &callbackErrorHandler{listeners: listeners, logger: NewNopLogger()}}
+
+	l := NewCircuitBreakingConnectionStateListener(NewConnectionStateListener(func(client CuratorFramework, newState ConnectionState) {
+		if newState == RECONNECTED {
+			panic("boom")
+		}
+	}), NewRetryOneTime(10*time.Millisecond))
+
+	l.StateChanged(client, SUSPENDED)
+	l.StateChanged(client, RECONNECTED)
+
+	// the consolidated state is delivered when the backoff period elapsed
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "ConnectionStateListener threw exception in StateChanged on RECONNECTED event, panic: boom")
+	case <-time.After(time.Second):
+		assert.Fail(t, "panic not reported")
+	}
+
+	assert.False(t, l.IsOpen())
+}
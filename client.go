@@ -2,7 +2,6 @@ package curator
 
 import (
 	"errors"
-	"log"
 	"strings"
 	"time"
 
@@ -63,6 +62,56 @@ type ZookeeperConnection interface {
 	Sync(path string) (string, error)
 }
 
+// The optional extension of ZookeeperConnection for the ZooKeeper 3.5+ servers,
+// which is used to create container and TTL nodes.
+//
+// The connection of DefaultZookeeperDialer implements it.
+// Its Multi should also accept the *zk.CreateRequest with CONTAINER mode and the *CreateTTLRequest.
+type ExtendedZookeeperConnection interface {
+	ZookeeperConnection
+
+	// Create a node with the given path and return its stat (the create2 or createContainer opcode)
+	Create2(path string, data []byte, flags int32, acl []zk.ACL) (string, *zk.Stat, error)
+
+	// Create a node with the given path and TTL, and return its stat (the createTTL opcode)
+	CreateTTL(path string, data []byte, flags int32, acl []zk.ACL, ttl time.Duration) (string, *zk.Stat, error)
+}
+
+// The optional extension of ZookeeperConnection for the ZooKeeper 3.6+ servers,
+// which is used to add the persistent watches and remove the watches on the server.
+//
+// The default connection doesn't implement it, so the persistent watches fail with ErrWatchesNotSupported,
+// and the watches are only removed locally, the server keeps them until they are triggered.
+type WatchesZookeeperConnection interface {
+	ZookeeperConnection
+
+	// Add a persistent watch on the given path (the addWatch opcode),
+	// the events will be sent to the returned channel until the watch is removed or the connection is closed.
+	// The connection should restore the watch when it reconnects in the same session,
+	// the watch is added again once a new session is established.
+	AddWatch(path string, mode int32) (<-chan zk.Event, error)
+
+	// Check the watches of the given type exist on the given path (the checkWatches opcode),
+	// return ErrNoWatcher if no such watches.
+	CheckWatches(path string, watcherType int32) error
+
+	// Remove all the watches of the given type on the given path (the removeWatches opcode),
+	// the channels of the removed watches will be closed, return ErrNoWatcher if no such watches.
+	RemoveWatches(path string, watcherType int32) error
+}
+
+// The optional extension of ZookeeperConnection which reports the session timeout negotiated with the server,
+// which is used to emulate the session expiration while the connection is suspended.
+type SessionZookeeperConnection interface {
+	ZookeeperConnection
+
+	// Return the negotiated session timeout, or 0 if the session has not been established
+	SessionTimeout() time.Duration
+
+	// Return the current session id, or 0 if the session has not been established
+	SessionID() int64
+}
+
 // Allocate a new ZooKeeper connection
 type ZookeeperDialer interface {
 	Dial(connString string, sessionTimeout time.Duration, canBeReadOnly bool) (ZookeeperConnection, <-chan zk.Event, error)
@@ -87,7 +136,13 @@ type DefaultZookeeperDialer struct {
 }
 
 func (d *DefaultZookeeperDialer) Dial(connString string, sessionTimeout time.Duration, canBeReadOnly bool) (ZookeeperConnection, <-chan zk.Event, error) {
-	return zk.ConnectWithDialer(strings.Split(connString, ","), sessionTimeout, d.Dialer)
+	conn, events, err := zk.ConnectWithDialer(strings.Split(connString, ","), sessionTimeout, d.Dialer)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &extendedConn{conn}, events, nil
 }
 
 // A wrapper around Zookeeper that takes care of some low-level housekeeping
@@ -123,13 +178,21 @@ type curatorZookeeperClient struct {
 	started      AtomicBool
 	TracerDriver TracerDriver
 	retryPolicy  RetryPolicy
+	classifier   RetryClassifier
+	logger       Logger
 }
 
 func NewCuratorZookeeperClient(zookeeperDialer ZookeeperDialer, ensembleProvider EnsembleProvider, sessionTimeout, connectionTimeout time.Duration,
 	watcher Watcher, retryPolicy RetryPolicy, canReadOnly bool, authInfos []AuthInfo) *curatorZookeeperClient {
 
+	return newCuratorZookeeperClient(zookeeperDialer, ensembleProvider, sessionTimeout, connectionTimeout, watcher, retryPolicy, canReadOnly, authInfos, DefaultLogger, nil)
+}
+
+func newCuratorZookeeperClient(zookeeperDialer ZookeeperDialer, ensembleProvider EnsembleProvider, sessionTimeout, connectionTimeout time.Duration,
+	watcher Watcher, retryPolicy RetryPolicy, canReadOnly bool, authInfos []AuthInfo, logger Logger, dispatcher *SerialExecutor) *curatorZookeeperClient {
+
 	if sessionTimeout < connectionTimeout {
-		log.Printf("session timeout [%d] is less than connection timeout [%d]", sessionTimeout, connectionTimeout)
+		logger.Warn("Session timeout is less than connection timeout", "sessionTimeout", sessionTimeout, "connectionTimeout", connectionTimeout)
 	}
 
 	if zookeeperDialer == nil {
@@ -154,10 +217,15 @@ func NewCuratorZookeeperClient(zookeeperDialer ZookeeperDialer, ensembleProvider
 
 	tracer := newDefaultTracerDriver()
 
+	state := newConnectionState(dialer, ensembleProvider, sessionTimeout, connectionTimeout, watcher, tracer, canReadOnly, dispatcher)
+
+	state.logger = logger
+
 	return &curatorZookeeperClient{
-		state:        newConnectionState(dialer, ensembleProvider, sessionTimeout, connectionTimeout, watcher, tracer, canReadOnly),
+		state:        state,
 		TracerDriver: tracer,
 		retryPolicy:  retryPolicy,
+		logger:       logger,
 	}
 }
 
@@ -188,7 +256,18 @@ func (c *curatorZookeeperClient) RetryPolicy() RetryPolicy {
 }
 
 func (c *curatorZookeeperClient) NewRetryLoop() RetryLoop {
-	return newRetryLoop(c.retryPolicy, c.TracerDriver)
+	return c.newTracedRetryLoop(nil)
+}
+
+// Create a retry loop which records the trace of the operation when it completes
+func (c *curatorZookeeperClient) newTracedRetryLoop(trace *OperationTrace) *retryLoop {
+	loop := newRetryLoop(c.retryPolicy, c.TracerDriver)
+
+	loop.classifier = c.classifier
+	loop.trace = trace
+	loop.sessionId = c.state.sessionId
+
+	return loop
 }
 
 func (c *curatorZookeeperClient) StartTracer(name string) Tracer {
@@ -0,0 +1,337 @@
+package curator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// The opcodes of the ZooKeeper 3.5+ servers which go-zookeeper doesn't send
+const (
+	opCreate          = 1
+	opDelete          = 2
+	opSetData         = 5
+	opCheck           = 13
+	opMulti           = 14
+	opCreate2         = 15
+	opCreateContainer = 19
+	opCreateTTL       = 21
+	opError           = -1
+)
+
+// Send the request with the given opcode in the session of the connection and decode the response into res,
+// the request and response are encoded by go-zookeeper, either field by field or through their Encode/Decode methods.
+//
+//go:linkname zkRequest github.com/samuel/go-zookeeper/zk.(*Conn).request
+func zkRequest(conn *zk.Conn, opcode int32, req interface{}, res interface{}, recvFunc unsafe.Pointer) (int64, error)
+
+// The default connection, which implements ExtendedZookeeperConnection over *zk.Conn
+// by sending the create2, createContainer and createTTL opcodes itself.
+type extendedConn struct {
+	*zk.Conn
+}
+
+type createTTLRequest struct {
+	Path  string
+	Data  []byte
+	Acl   []zk.ACL
+	Flags int32
+	TTL   int64 // in milliseconds
+}
+
+type create2Response struct {
+	Path string
+	Stat zk.Stat
+}
+
+func (c *extendedConn) Create2(path string, data []byte, flags int32, acl []zk.ACL) (string, *zk.Stat, error) {
+	var opcode int32 = opCreate2
+
+	if CreateMode(flags).IsContainer() {
+		opcode = opCreateContainer
+	}
+
+	res := &create2Response{}
+
+	if _, err := zkRequest(c.Conn, opcode, &zk.CreateRequest{Path: path, Data: data, Acl: acl, Flags: flags}, res, nil); err != nil {
+		return "", nil, err
+	}
+
+	return res.Path, &res.Stat, nil
+}
+
+func (c *extendedConn) CreateTTL(path string, data []byte, flags int32, acl []zk.ACL, ttl time.Duration) (string, *zk.Stat, error) {
+	res := &create2Response{}
+
+	if _, err := zkRequest(c.Conn, opCreateTTL, &createTTLRequest{path, data, acl, flags, ttl.Milliseconds()}, res, nil); err != nil {
+		return "", nil, err
+	}
+
+	return res.Path, &res.Stat, nil
+}
+
+// Executes multiple ZooKeeper operations or none of them,
+// go-zookeeper is used unless the operations create container or TTL nodes.
+func (c *extendedConn) Multi(ops ...interface{}) ([]zk.MultiResponse, error) {
+	extended := false
+
+	for _, op := range ops {
+		switch req := op.(type) {
+		case *zk.CreateRequest:
+			extended = extended || CreateMode(req.Flags).IsContainer()
+		case *CreateTTLRequest:
+			extended = true
+		}
+	}
+
+	if !extended {
+		return c.Conn.Multi(ops...)
+	}
+
+	res := &multiResponse{}
+
+	_, err := zkRequest(c.Conn, opMulti, &multiRequest{ops}, res, nil)
+
+	return res.results, err
+}
+
+// The multi request with the container and TTL nodes, which go-zookeeper can't encode
+type multiRequest struct {
+	ops []interface{}
+}
+
+func (r *multiRequest) Encode(buf []byte) (int, error) {
+	var w juteWriter
+
+	for _, op := range r.ops {
+		switch req := op.(type) {
+		case *zk.CreateRequest:
+			if CreateMode(req.Flags).IsContainer() {
+				w.header(opCreateContainer, false)
+			} else {
+				w.header(opCreate, false)
+			}
+
+			w.string(req.Path)
+			w.buffer(req.Data)
+			w.acl(req.Acl)
+			w.int32(req.Flags)
+		case *CreateTTLRequest:
+			w.header(opCreateTTL, false)
+			w.string(req.Path)
+			w.buffer(req.Data)
+			w.acl(req.Acl)
+			w.int32(req.Flags)
+			w.int64(req.TTL.Milliseconds())
+		case *zk.DeleteRequest:
+			w.header(opDelete, false)
+			w.string(req.Path)
+			w.int32(req.Version)
+		case *zk.SetDataRequest:
+			w.header(opSetData, false)
+			w.string(req.Path)
+			w.buffer(req.Data)
+			w.int32(req.Version)
+		case *zk.CheckVersionRequest:
+			w.header(opCheck, false)
+			w.string(req.Path)
+			w.int32(req.Version)
+		default:
+			return 0, fmt.Errorf("unknown operation type %T", op)
+		}
+	}
+
+	w.header(opError, true)
+
+	if len(w.buf) > len(buf) {
+		return 0, zk.ErrShortBuffer
+	}
+
+	return copy(buf, w.buf), nil
+}
+
+// The results of the multi request, the created nodes return their stats as create2
+type multiResponse struct {
+	results []zk.MultiResponse
+}
+
+func (r *multiResponse) Decode(buf []byte) (int, error) {
+	rd := juteReader{buf: buf}
+
+	for {
+		opcode, done, code := rd.int32(), rd.bool(), rd.int32()
+
+		if rd.err != nil || done {
+			return rd.off, rd.err
+		}
+
+		var res zk.MultiResponse
+
+		switch opcode {
+		case opCreate:
+			res.String = rd.string()
+		case opCreate2, opCreateContainer, opCreateTTL:
+			res.String = rd.string()
+			res.Stat = rd.stat()
+		case opSetData:
+			res.Stat = rd.stat()
+		case opDelete, opCheck:
+		case opError:
+			code = rd.int32()
+		default:
+			return rd.off, zk.ErrAPIError
+		}
+
+		res.Error = errorOfCode(code)
+
+		r.results = append(r.results, res)
+	}
+}
+
+// The errors of the multi operations, go-zookeeper doesn't export its mapping
+var errorsOfCodes = map[int32]error{
+	-100: zk.ErrAPIError,
+	-101: zk.ErrNoNode,
+	-102: zk.ErrNoAuth,
+	-103: zk.ErrBadVersion,
+	-108: zk.ErrNoChildrenForEphemerals,
+	-110: zk.ErrNodeExists,
+	-111: zk.ErrNotEmpty,
+	-112: zk.ErrSessionExpired,
+	-114: zk.ErrInvalidACL,
+	-115: zk.ErrAuthFailed,
+	-116: zk.ErrClosing,
+	-117: zk.ErrNothing,
+	-118: zk.ErrSessionMoved,
+}
+
+func errorOfCode(code int32) error {
+	if code == 0 {
+		return nil
+	} else if err, ok := errorsOfCodes[code]; ok {
+		return err
+	}
+
+	return zk.ErrUnknown
+}
+
+// Encode the values in the jute format of ZooKeeper
+type juteWriter struct {
+	buf []byte
+}
+
+func (w *juteWriter) int32(v int32) { w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v)) }
+
+func (w *juteWriter) int64(v int64) { w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v)) }
+
+func (w *juteWriter) bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *juteWriter) string(v string) {
+	w.int32(int32(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *juteWriter) buffer(v []byte) {
+	if v == nil {
+		w.int32(-1)
+	} else {
+		w.int32(int32(len(v)))
+		w.buf = append(w.buf, v...)
+	}
+}
+
+func (w *juteWriter) acl(acls []zk.ACL) {
+	w.int32(int32(len(acls)))
+
+	for _, acl := range acls {
+		w.int32(acl.Perms)
+		w.string(acl.Scheme)
+		w.string(acl.ID)
+	}
+}
+
+func (w *juteWriter) header(opcode int32, done bool) {
+	w.int32(opcode)
+	w.bool(done)
+	w.int32(-1)
+}
+
+// Decode the values in the jute format of ZooKeeper, the first error stops the decoding
+type juteReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (r *juteReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	} else if n < 0 || r.off+n > len(r.buf) {
+		r.err = zk.ErrShortBuffer
+
+		return nil
+	}
+
+	b := r.buf[r.off : r.off+n]
+
+	r.off += n
+
+	return b
+}
+
+func (r *juteReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+
+	return 0
+}
+
+func (r *juteReader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+
+	return 0
+}
+
+func (r *juteReader) bool() bool {
+	if b := r.next(1); b != nil {
+		return b[0] != 0
+	}
+
+	return false
+}
+
+func (r *juteReader) string() string {
+	if n := r.int32(); n > 0 {
+		return string(r.next(int(n)))
+	}
+
+	return ""
+}
+
+func (r *juteReader) stat() *zk.Stat {
+	return &zk.Stat{
+		Czxid:          r.int64(),
+		Mzxid:          r.int64(),
+		Ctime:          r.int64(),
+		Mtime:          r.int64(),
+		Version:        r.int32(),
+		Cversion:       r.int32(),
+		Aversion:       r.int32(),
+		EphemeralOwner: r.int64(),
+		DataLength:     r.int32(),
+		NumChildren:    r.int32(),
+		Pzxid:          r.int64(),
+	}
+}
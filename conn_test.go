@@ -0,0 +1,94 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiRequest(t *testing.T) {
+	req := &multiRequest{[]interface{}{
+		&zk.CreateRequest{Path: "/a", Data: []byte("x"), Flags: int32(CONTAINER)},
+		&CreateTTLRequest{Path: "/b", Flags: int32(PERSISTENT_WITH_TTL), TTL: time.Second},
+		&zk.DeleteRequest{Path: "/c", Version: 3},
+	}}
+
+	var w juteWriter
+
+	w.header(opCreateContainer, false)
+	w.string("/a")
+	w.buffer([]byte("x"))
+	w.acl(nil)
+	w.int32(int32(CONTAINER))
+	w.header(opCreateTTL, false)
+	w.string("/b")
+	w.buffer(nil)
+	w.acl(nil)
+	w.int32(int32(PERSISTENT_WITH_TTL))
+	w.int64(1000)
+	w.header(opDelete, false)
+	w.string("/c")
+	w.int32(3)
+	w.header(opError, true)
+
+	buf := make([]byte, 1024)
+
+	n, err := req.Encode(buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, w.buf, buf[:n])
+
+	_, err = req.Encode(buf[:n-1])
+
+	assert.Equal(t, zk.ErrShortBuffer, err)
+
+	_, err = (&multiRequest{[]interface{}{"/d"}}).Encode(buf)
+
+	assert.EqualError(t, err, "unknown operation type string")
+}
+
+func TestMultiResponse(t *testing.T) {
+	var w juteWriter
+
+	w.int32(opCreate2)
+	w.bool(false)
+	w.int32(0)
+	w.string("/a")
+
+	for i := 0; i < 4; i++ {
+		w.int64(int64(i + 1))
+	}
+	for i := 0; i < 3; i++ {
+		w.int32(int32(i + 5))
+	}
+	w.int64(8)
+	w.int32(9)
+	w.int32(10)
+	w.int64(11)
+
+	w.int32(opError)
+	w.bool(false)
+	w.int32(-110)
+	w.int32(-110)
+	w.header(opError, true)
+
+	res := &multiResponse{}
+
+	n, err := res.Decode(w.buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(w.buf), n)
+	assert.Equal(t, []zk.MultiResponse{
+		{String: "/a", Stat: &zk.Stat{
+			Czxid: 1, Mzxid: 2, Ctime: 3, Mtime: 4, Version: 5, Cversion: 6, Aversion: 7,
+			EphemeralOwner: 8, DataLength: 9, NumChildren: 10, Pzxid: 11,
+		}},
+		{Error: zk.ErrNodeExists},
+	}, res.results)
+
+	_, err = (&multiResponse{}).Decode(w.buf[:10])
+
+	assert.Equal(t, zk.ErrShortBuffer, err)
+}
@@ -1,16 +1,37 @@
 package curator
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
+// The prefix of the node name created in protected mode
+const PROTECTED_PREFIX = "_c_"
+
+// The source of the random GUIDs of the protected nodes
+var protectedIdReader io.Reader = rand.Reader
+
 type createBuilder struct {
-	client                *curatorFramework
-	createMode            CreateMode
-	backgrounding         backgrounding
-	createParentsIfNeeded bool
-	compress              bool
-	acling                acling
+	client                    *curatorFramework
+	ctx                       context.Context
+	createMode                CreateMode
+	backgrounding             backgrounding
+	createParentsIfNeeded     bool
+	createParentsAsContainers bool
+	ttl                       time.Duration
+	compress                  bool
+	acling                    acling
+	doProtected               bool
+	protectedId               string
+	setDataIfExists           bool
+	setDataVersion            int32
+	stat                      *zk.Stat
 }
 
 func (b *createBuilder) ForPath(path string) (string, error) {
@@ -18,6 +39,14 @@ func (b *createBuilder) ForPath(path string) (string, error) {
 }
 
 func (b *createBuilder) ForPathWithData(givenPath string, payload []byte) (string, error) {
+	if b.createMode.IsTTL() != (b.ttl > 0) {
+		return "", ErrInvalidTTL
+	}
+
+	if err := b.client.schemaSet.ValidateCreate(givenPath, b.createMode, payload); err != nil {
+		return "", err
+	}
+
 	if b.compress {
 		if data, err := b.client.compressionProvider.Compress(givenPath, payload); err != nil {
 			return "", err
@@ -28,8 +57,20 @@ func (b *createBuilder) ForPathWithData(givenPath string, payload []byte) (strin
 
 	adjustedPath := b.client.fixForNamespace(givenPath, b.createMode.IsSequential())
 
+	if b.doProtected {
+		if id, err := newProtectedId(); err != nil {
+			return "", err
+		} else {
+			b.protectedId = id
+		}
+
+		adjustedPath = adjustPathForProtection(adjustedPath, b.protectedId)
+	}
+
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, payload, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, payload, givenPath) }, b.backgrounding, CREATE, givenPath); err != nil {
+			return "", err
+		}
 
 		return b.client.unfixForNamespace(adjustedPath), nil
 	} else {
@@ -52,6 +93,7 @@ func (b *createBuilder) pathInBackground(path string, payload []byte, givenPath
 			err:       err,
 			path:      createdPath,
 			data:      payload,
+			stat:      b.stat,
 			acls:      b.acling.getAclList(path),
 			context:   b.backgrounding.context,
 		}
@@ -62,48 +104,188 @@ func (b *createBuilder) pathInBackground(path string, payload []byte, givenPath
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *createBuilder) pathInForeground(path string, payload []byte) (string, error) {
 	zkClient := b.client.ZookeeperClient()
+	firstTime := true
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "create", Name: "createBuilder.pathInForeground", Path: path, RequestBytes: len(payload)}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
-			createdPath, err := conn.Create(path, payload, int32(b.createMode), b.acling.getAclList(path))
+			if b.doProtected && !firstTime {
+				// the previous attempt may have created the node before the connection was lost
+				if createdPath, err := findProtectedNode(conn, path, b.protectedId); err != nil {
+					return nil, err
+				} else if len(createdPath) > 0 {
+					return &attemptResult{value: createdPath}, nil
+				}
+			}
+
+			firstTime = false
+
+			createdPath, stat, err := createNode(conn, path, payload, b.createMode, b.acling.getAclList(path), b.ttl)
 
 			if err == zk.ErrNoNode && b.createParentsIfNeeded {
-				if err := MakeDirs(conn, path, false, b.acling.aclProvider); err != nil {
-					return "", err
+				if err := makeDirs(conn, path, false, b.acling.aclProvider, b.createParentsAsContainers); err != nil {
+					return nil, err
+				}
+
+				createdPath, stat, err = createNode(conn, path, payload, b.createMode, b.acling.getAclList(path), b.ttl)
+			}
+
+			if err == zk.ErrNodeExists && b.setDataIfExists {
+				if stat, err = conn.Set(path, payload, b.setDataVersion); err != nil {
+					return nil, err
 				}
 
-				return conn.Create(path, payload, int32(b.createMode), b.acling.getAclList(path))
-			} else {
-				return createdPath, err
+				createdPath = path
+			} else if err != nil {
+				return nil, err
+			} else if b.stat != nil && stat == nil {
+				if _, stat, err = conn.Exists(createdPath); err != nil {
+					return nil, err
+				}
 			}
+
+			return &attemptResult{value: createdPath, stat: stat}, nil
 		}
 	})
 
-	createdPath, _ := result.(string)
+	res, _ := result.(*attemptResult)
+
+	if res == nil {
+		return "", err
+	}
+
+	res.storeStatIn(&b.stat)
+
+	createdPath, _ := res.value.(string)
 
 	return createdPath, err
 }
 
+// Create a node with the given mode, the container and TTL nodes are created through ExtendedZookeeperConnection if supported.
+//
+// The container nodes fall back to the persistent nodes if the connection doesn't support them.
+func createNode(conn ZookeeperConnection, path string, payload []byte, mode CreateMode, acls []zk.ACL, ttl time.Duration) (string, *zk.Stat, error) {
+	if extConn, ok := conn.(ExtendedZookeeperConnection); ok {
+		if mode.IsTTL() {
+			return extConn.CreateTTL(path, payload, int32(mode), acls, ttl)
+		} else if mode.IsContainer() {
+			return extConn.Create2(path, payload, int32(mode), acls)
+		}
+	} else if mode.IsTTL() {
+		return "", nil, ErrTTLNotSupported
+	} else if mode.IsContainer() {
+		mode = PERSISTENT
+	}
+
+	createdPath, err := conn.Create(path, payload, int32(mode), acls)
+
+	return createdPath, nil, err
+}
+
+// Generate a random GUID used to mark the nodes created in protected mode,
+// fail instead of using a predictable id which could match the nodes of the other clients
+func newProtectedId() (string, error) {
+	var id [16]byte
+
+	if _, err := io.ReadFull(protectedIdReader, id[:]); err != nil {
+		return "", err
+	}
+
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]), nil
+}
+
+func protectedPrefix(protectedId string) string {
+	return PROTECTED_PREFIX + protectedId + "-"
+}
+
+// Prefix the node name with the protected GUID, i.e. "/parent/node" will become "/parent/_c_<GUID>-node"
+func adjustPathForProtection(path, protectedId string) string {
+	pathAndNode, _ := SplitPath(path)
+
+	return JoinPath(pathAndNode.Path, protectedPrefix(protectedId)+pathAndNode.Node)
+}
+
+// Search the children of the parent node for the node created with the protected GUID
+func findProtectedNode(conn ZookeeperConnection, path, protectedId string) (string, error) {
+	pathAndNode, _ := SplitPath(path)
+
+	children, _, err := conn.Children(pathAndNode.Path)
+
+	if err == zk.ErrNoNode {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	prefix := protectedPrefix(protectedId)
+
+	for _, child := range children {
+		if strings.HasPrefix(child, prefix) {
+			return JoinPath(pathAndNode.Path, child), nil
+		}
+	}
+
+	return "", nil
+}
+
 func (b *createBuilder) CreatingParentsIfNeeded() CreateBuilder {
 	b.createParentsIfNeeded = true
 
 	return b
 }
 
+func (b *createBuilder) CreatingParentContainersIfNeeded() CreateBuilder {
+	b.createParentsIfNeeded = true
+	b.createParentsAsContainers = true
+
+	return b
+}
+
+func (b *createBuilder) WithProtection() CreateBuilder {
+	b.doProtected = true
+
+	return b
+}
+
+func (b *createBuilder) OrSetData() CreateBuilder {
+	return b.OrSetDataWithVersion(AnyVersion)
+}
+
+func (b *createBuilder) OrSetDataWithVersion(version int32) CreateBuilder {
+	b.setDataIfExists = true
+	b.setDataVersion = version
+
+	return b
+}
+
+func (b *createBuilder) StoringStatIn(stat *zk.Stat) CreateBuilder {
+	b.stat = stat
+
+	return b
+}
+
 func (b *createBuilder) WithMode(mode CreateMode) CreateBuilder {
 	b.createMode = mode
 
 	return b
 }
 
+func (b *createBuilder) WithTTL(ttl time.Duration) CreateBuilder {
+	b.ttl = ttl
+
+	return b
+}
+
 func (b *createBuilder) WithACL(acls ...zk.ACL) CreateBuilder {
 	b.acling.aclList = acls
 
@@ -116,6 +298,12 @@ func (b *createBuilder) Compressed() CreateBuilder {
 	return b
 }
 
+func (b *createBuilder) WithContext(ctx context.Context) CreateBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *createBuilder) InBackground() CreateBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
@@ -1,11 +1,16 @@
 package curator
 
 import (
+	"crypto/rand"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -97,3 +102,145 @@ func (s *CreateBuilderTestSuite) TestCreateParents() {
 		assert.Equal(s.T(), err, zk.ErrAPIError)
 	})
 }
+
+func (s *CreateBuilderTestSuite) TestOrSetData() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, version int32, stat *zk.Stat, acls []zk.ACL) {
+		conn.On("Create", "/node", data, int32(PERSISTENT), acls).Return("", zk.ErrNodeExists).Once()
+		conn.On("Set", "/node", data, version).Return(stat, nil).Once()
+
+		var nodeStat zk.Stat
+
+		path, err := client.Create().OrSetDataWithVersion(version).WithACL(acls...).StoringStatIn(&nodeStat).ForPathWithData("/node", data)
+
+		assert.Equal(s.T(), "/node", path)
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), *stat, nodeStat)
+	})
+}
+
+func (s *CreateBuilderTestSuite) TestOrSetDataWithParents() {
+	s.WithNamespace("parent", func(client CuratorFramework, conn *mockConn, wg *sync.WaitGroup, data []byte, stat *zk.Stat, aclProvider *mockACLProvider, acls []zk.ACL) {
+		conn.On("Exists", "/parent").Return(true, nil, nil).Twice()
+		conn.On("Create", "/parent/child/node", data, int32(PERSISTENT), acls).Return("", zk.ErrNoNode).Once()
+		conn.On("Exists", "/parent/child").Return(false, nil, nil).Once()
+		aclProvider.On("GetAclForPath", "/parent/child").Return(OPEN_ACL_UNSAFE).Once()
+		conn.On("Create", "/parent/child", []byte{}, int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/parent/child", nil).Once()
+		conn.On("Create", "/parent/child/node", data, int32(PERSISTENT), acls).Return("", zk.ErrNodeExists).Once()
+		conn.On("Set", "/parent/child/node", data, AnyVersion).Return(stat, nil).Once()
+
+		_, err := client.Create().CreatingParentsIfNeeded().OrSetData().WithACL(acls...).InBackgroundWithCallback(
+			func(client CuratorFramework, event CuratorEvent) error {
+				defer wg.Done()
+
+				assert.Equal(s.T(), CREATE, event.Type())
+				assert.NoError(s.T(), event.Err())
+				assert.Equal(s.T(), stat, event.Stat())
+
+				return nil
+			}).ForPathWithData("/child/node", data)
+
+		assert.NoError(s.T(), err)
+	})
+}
+
+func (s *CreateBuilderTestSuite) TestStoringStat() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat, acls []zk.ACL) {
+		conn.On("Create", "/node", data, int32(PERSISTENT), acls).Return("/node", nil).Once()
+		conn.On("Exists", "/node").Return(true, stat, nil).Once()
+
+		var nodeStat zk.Stat
+
+		path, err := client.Create().WithACL(acls...).StoringStatIn(&nodeStat).ForPathWithData("/node", data)
+
+		assert.Equal(s.T(), "/node", path)
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), *stat, nodeStat)
+	})
+}
+
+func (s *CreateBuilderTestSuite) TestTTL() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, acls []zk.ACL) {
+		_, err := client.Create().WithMode(PERSISTENT_WITH_TTL).ForPathWithData("/node", data)
+
+		assert.Equal(s.T(), ErrInvalidTTL, err)
+
+		_, err = client.Create().WithTTL(time.Minute).ForPathWithData("/node", data)
+
+		assert.Equal(s.T(), ErrInvalidTTL, err)
+
+		_, err = client.Create().WithMode(PERSISTENT_WITH_TTL).WithTTL(time.Minute).WithACL(acls...).ForPathWithData("/node", data)
+
+		assert.Equal(s.T(), ErrTTLNotSupported, err)
+	})
+}
+
+func TestCreateNode(t *testing.T) {
+	conn := &mockExtendedConn{}
+	data := []byte("data")
+	stat := &zk.Stat{Version: 1}
+
+	conn.On("CreateTTL", "/node", data, int32(PERSISTENT_SEQUENTIAL_WITH_TTL), OPEN_ACL_UNSAFE, time.Minute).Return("/node0000000001", stat, nil).Once()
+	conn.On("Create2", "/container", data, int32(CONTAINER), OPEN_ACL_UNSAFE).Return("/container", stat, nil).Once()
+	conn.On("Create", "/node", data, int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/node", nil).Once()
+
+	path, nodeStat, err := createNode(conn, "/node", data, PERSISTENT_SEQUENTIAL_WITH_TTL, OPEN_ACL_UNSAFE, time.Minute)
+
+	assert.Equal(t, "/node0000000001", path)
+	assert.Equal(t, stat, nodeStat)
+	assert.NoError(t, err)
+
+	path, nodeStat, err = createNode(conn, "/container", data, CONTAINER, OPEN_ACL_UNSAFE, 0)
+
+	assert.Equal(t, "/container", path)
+	assert.Equal(t, stat, nodeStat)
+	assert.NoError(t, err)
+
+	path, nodeStat, err = createNode(conn, "/node", data, PERSISTENT, OPEN_ACL_UNSAFE, 0)
+
+	assert.Equal(t, "/node", path)
+	assert.Nil(t, nodeStat)
+	assert.NoError(t, err)
+
+	conn.AssertExpectations(t)
+}
+
+func (s *CreateBuilderTestSuite) TestProtection() {
+	s.With(func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, data []byte, acls []zk.ACL) {
+		var protectedNode string
+
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(true).Once()
+
+		children := conn.On("Children", "/parent").Return([]string{"other"}, nil, nil).Once()
+
+		conn.On("Create", mock.MatchedBy(func(path string) bool {
+			return strings.HasPrefix(path, "/parent/"+PROTECTED_PREFIX) && strings.HasSuffix(path, "-child")
+		}), data, int32(EPHEMERAL_SEQUENTIAL), acls).Run(func(args mock.Arguments) {
+			// the node was created, but the connection was lost before the response
+			protectedNode = GetNodeFromPath(args.String(0)) + "0000000001"
+
+			children.Return([]string{"other", protectedNode}, nil, nil)
+		}).Return("", zk.ErrSessionExpired).Once()
+
+		path, err := client.Create().WithProtection().WithMode(EPHEMERAL_SEQUENTIAL).WithACL(acls...).ForPathWithData("/parent/child", data)
+
+		assert.Equal(s.T(), "/parent/"+protectedNode, path)
+		assert.NoError(s.T(), err)
+	})
+}
+
+type failingReader struct{}
+
+func (r failingReader) Read(p []byte) (int, error) { return 0, errors.New("no entropy") }
+
+func (s *CreateBuilderTestSuite) TestProtectionWithoutRandom() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte) {
+		protectedIdReader = failingReader{}
+
+		defer func() { protectedIdReader = rand.Reader }()
+
+		path, err := client.Create().WithProtection().WithMode(EPHEMERAL_SEQUENTIAL).ForPathWithData("/parent/child", data)
+
+		assert.Empty(s.T(), path)
+		assert.EqualError(s.T(), err, "no entropy")
+	})
+}
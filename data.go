@@ -1,11 +1,14 @@
 package curator
 
 import (
+	"context"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
 type getDataBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	decompress    bool
 	stat          *zk.Stat
@@ -13,10 +16,18 @@ type getDataBuilder struct {
 }
 
 func (b *getDataBuilder) ForPath(givenPath string) ([]byte, error) {
+	if b.watching.watched || b.watching.watcher != nil {
+		if err := b.client.schemaSet.ValidateWatch(givenPath); err != nil {
+			return nil, err
+		}
+	}
+
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, GET_DATA, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	}
@@ -51,41 +62,28 @@ func (b *getDataBuilder) pathInBackground(adjustedPath, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *getDataBuilder) pathInForeground(path string) ([]byte, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "getData", Name: "getDataBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
 			var data []byte
-			var stat *zk.Stat
-			var events <-chan zk.Event
+			var res attemptResult
 			var err error
 
 			if b.watching.watched || b.watching.watcher != nil {
-				data, stat, events, err = conn.GetW(path)
-
-				if events != nil && b.watching.watcher != nil {
-					go NewWatchers(b.watching.watcher).Watch(events)
-				}
+				data, res.stat, res.events, err = conn.GetW(path)
 			} else {
-				data, stat, err = conn.Get(path)
+				data, res.stat, err = conn.Get(path)
 			}
 
-			if stat != nil {
-				if b.stat != nil {
-					*b.stat = *stat
-				} else {
-					b.stat = stat
-				}
-			}
-
-			if b.decompress {
+			if err == nil && b.decompress {
 				if payload, err := b.client.compressionProvider.Decompress(path, data); err != nil {
 					return nil, err
 				} else {
@@ -93,11 +91,25 @@ func (b *getDataBuilder) pathInForeground(path string) ([]byte, error) {
 				}
 			}
 
-			return data, err
+			res.value = data
+
+			return &res, err
 		}
 	})
 
-	data, _ := result.([]byte)
+	res, _ := result.(*attemptResult)
+
+	if res == nil {
+		return nil, err
+	}
+
+	res.storeStatIn(&b.stat)
+
+	if res.events != nil && b.watching.watcher != nil {
+		b.client.client.state.watches.watch(path, WATCHER_DATA, b.watching.watcher, res.events)
+	}
+
+	data, _ := res.value.([]byte)
 
 	return data, err
 }
@@ -121,7 +133,13 @@ func (b *getDataBuilder) Watched() GetDataBuilder {
 }
 
 func (b *getDataBuilder) UsingWatcher(watcher Watcher) GetDataBuilder {
-	b.watching.watcher = b.client.getNamespaceWatcher(watcher)
+	b.watching.watcher = b.client.wrapWatcher(watcher)
+
+	return b
+}
+
+func (b *getDataBuilder) WithContext(ctx context.Context) GetDataBuilder {
+	b.ctx = ctx
 
 	return b
 }
@@ -152,6 +170,7 @@ func (b *getDataBuilder) InBackgroundWithCallbackAndContext(callback BackgroundC
 
 type setDataBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	version       int32
 	compress      bool
@@ -162,6 +181,10 @@ func (b *setDataBuilder) ForPath(path string) (*zk.Stat, error) {
 }
 
 func (b *setDataBuilder) ForPathWithData(givenPath string, payload []byte) (*zk.Stat, error) {
+	if err := b.client.schemaSet.ValidateData(givenPath, payload); err != nil {
+		return nil, err
+	}
+
 	if b.compress {
 		if data, err := b.client.compressionProvider.Compress(givenPath, payload); err != nil {
 			return nil, err
@@ -173,7 +196,9 @@ func (b *setDataBuilder) ForPathWithData(givenPath string, payload []byte) (*zk.
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, payload, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, payload, givenPath) }, b.backgrounding, SET_DATA, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	} else {
@@ -204,14 +229,14 @@ func (b *setDataBuilder) pathInBackground(path string, payload []byte, givenPath
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *setDataBuilder) pathInForeground(path string, payload []byte) (*zk.Stat, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "setData", Name: "setDataBuilder.pathInForeground", Path: path, RequestBytes: len(payload)}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
@@ -236,6 +261,12 @@ func (b *setDataBuilder) Compressed() SetDataBuilder {
 	return b
 }
 
+func (b *setDataBuilder) WithContext(ctx context.Context) SetDataBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *setDataBuilder) InBackground() SetDataBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
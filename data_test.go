@@ -1,11 +1,14 @@
 package curator
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -95,6 +98,60 @@ func (s *GetDataBuilderTestSuite) TestWatcher() {
 	})
 }
 
+func (s *GetDataBuilderTestSuite) TestContext() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+
+		data2, err := client.GetData().WithContext(ctx).ForPath("/node")
+
+		assert.Equal(s.T(), data, data2)
+		assert.NoError(s.T(), err)
+
+		cancel()
+
+		data2, err = client.GetData().WithContext(ctx).ForPath("/node")
+
+		assert.Nil(s.T(), data2)
+		assert.Equal(s.T(), context.Canceled, err)
+	})
+}
+
+func (s *GetDataBuilderTestSuite) TestAbandonedAttempt() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		ctx, cancel := context.WithCancel(context.Background())
+		called := make(chan struct{})
+		release := make(chan struct{})
+
+		conn.On("Get", "/node").Run(func(args mock.Arguments) {
+			close(called)
+
+			<-release
+		}).Return(data, stat, nil).Once()
+
+		go func() {
+			<-called
+
+			cancel()
+		}()
+
+		var stat2 zk.Stat
+
+		data2, err := client.GetData().WithContext(ctx).StoringStatIn(&stat2).ForPath("/node")
+
+		assert.Nil(s.T(), data2)
+		assert.Equal(s.T(), context.Canceled, err)
+
+		// the abandoned request completes after the caller got ctx.Err()
+		close(release)
+
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal(s.T(), zk.Stat{}, stat2)
+	})
+}
+
 type SetDataBuilderTestSuite struct {
 	mockContainerTestSuite
 }
@@ -152,3 +209,24 @@ func (s *SetDataBuilderTestSuite) TestBackground() {
 		assert.NoError(s.T(), err)
 	})
 }
+
+func (s *SetDataBuilderTestSuite) TestBackgroundWithContext() {
+	s.With(func(client CuratorFramework, conn *mockConn, wg *sync.WaitGroup, data []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+
+		defer cancel()
+
+		_, err := client.SetData().WithContext(ctx).InBackgroundWithCallback(
+			func(client CuratorFramework, event CuratorEvent) error {
+				defer wg.Done()
+
+				assert.Equal(s.T(), SET_DATA, event.Type())
+				assert.Equal(s.T(), "/node", event.Path())
+				assert.Equal(s.T(), context.DeadlineExceeded, event.Err())
+
+				return nil
+			}).ForPathWithData("/node", data)
+
+		assert.NoError(s.T(), err)
+	})
+}
@@ -1,21 +1,34 @@
 package curator
 
 import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
 type deleteBuilder struct {
 	client                   *curatorFramework
+	ctx                      context.Context
 	backgrounding            backgrounding
 	deletingChildrenIfNeeded bool
 	version                  int32
+	guaranteed               bool
 }
 
 func (b *deleteBuilder) ForPath(givenPath string) error {
+	if err := b.client.schemaSet.ValidateDelete(givenPath); err != nil {
+		return err
+	}
+
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, DELETE, givenPath); err != nil {
+			return err
+		}
 
 		return nil
 	} else {
@@ -44,14 +57,14 @@ func (b *deleteBuilder) pathInBackground(path string, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *deleteBuilder) pathInForeground(path string, givenPath string) error {
 	zkClient := b.client.ZookeeperClient()
 
-	_, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	_, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "delete", Name: "deleteBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		conn, err := zkClient.Conn()
 
 		if err == nil {
@@ -65,6 +78,10 @@ func (b *deleteBuilder) pathInForeground(path string, givenPath string) error {
 		return nil, err
 	})
 
+	if err != nil && b.guaranteed && isConnectionError(err) {
+		b.client.failedDeleteManager.addFailedDelete(path, b.version, b.deletingChildrenIfNeeded, err)
+	}
+
 	return err
 }
 
@@ -74,12 +91,24 @@ func (b *deleteBuilder) DeletingChildrenIfNeeded() DeleteBuilder {
 	return b
 }
 
+func (b *deleteBuilder) Guaranteed() DeleteBuilder {
+	b.guaranteed = true
+
+	return b
+}
+
 func (b *deleteBuilder) WithVersion(version int32) DeleteBuilder {
 	b.version = version
 
 	return b
 }
 
+func (b *deleteBuilder) WithContext(ctx context.Context) DeleteBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *deleteBuilder) InBackground() DeleteBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
@@ -103,3 +132,121 @@ func (b *deleteBuilder) InBackgroundWithCallbackAndContext(callback BackgroundCa
 
 	return b
 }
+
+// return true if the error was caused by a connection problem rather than the operation itself
+func isConnectionError(err error) bool {
+	for _, connErr := range []error{ErrConnectionClosed, ErrConnectionLoss, ErrSessionExpired, ErrSessionMoved, ErrTimeout} {
+		if errors.Is(err, connErr) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// Receives notifications about the guaranteed deletes that failed because of connection problems
+type FailedDeleteListener interface {
+	// Called when a failed delete has been recorded and will be retried in the background
+	PathAddedForDelete(path string, err error)
+
+	// Called when a recorded delete has been retried, err is nil if the node was deleted or is already gone
+	PathRetriedForDelete(path string, err error)
+}
+
+type failedDelete struct {
+	version                  int32
+	deletingChildrenIfNeeded bool
+}
+
+// Keeps the failed guaranteed deletes, and retries them in the background whenever the connection is (re)established
+type failedDeleteManager struct {
+	client    *curatorFramework
+	listener  FailedDeleteListener
+	lock      sync.Mutex
+	pending   map[string]failedDelete
+	retrying  AtomicBool
+	requested AtomicBool // another pass was requested while retrying
+}
+
+func newFailedDeleteManager(client *curatorFramework, listener FailedDeleteListener) *failedDeleteManager {
+	return &failedDeleteManager{
+		client:   client,
+		listener: listener,
+		pending:  make(map[string]failedDelete),
+	}
+}
+
+func (m *failedDeleteManager) addFailedDelete(path string, version int32, deletingChildrenIfNeeded bool, err error) {
+	m.lock.Lock()
+
+	m.pending[path] = failedDelete{version, deletingChildrenIfNeeded}
+
+	m.lock.Unlock()
+
+	if m.listener != nil {
+		m.listener.PathAddedForDelete(path, err)
+	}
+
+	if m.client.stateManager.Connected() {
+		m.client.runInBackground(m.retryFailedDeletes)
+	}
+}
+
+func (m *failedDeleteManager) StateChanged(client CuratorFramework, newState ConnectionState) {
+	if newState == CONNECTED || newState == RECONNECTED {
+		m.client.runInBackground(m.retryFailedDeletes)
+	}
+}
+
+func (m *failedDeleteManager) retryFailedDeletes() {
+	m.requested.Set(true)
+
+	// the deletes failed during a pass are retried in another pass by the goroutine already retrying
+	for m.requested.Load() && m.retrying.CompareAndSwap(false, true) {
+		for m.requested.Swap(false) {
+			m.retryPendingDeletes()
+		}
+
+		m.retrying.Set(false)
+	}
+}
+
+func (m *failedDeleteManager) retryPendingDeletes() {
+	m.lock.Lock()
+
+	pending := make(map[string]failedDelete, len(m.pending))
+
+	for path, op := range m.pending {
+		pending[path] = op
+	}
+
+	m.lock.Unlock()
+
+	for path, op := range pending {
+		b := &deleteBuilder{
+			client:                   m.client,
+			version:                  op.version,
+			deletingChildrenIfNeeded: op.deletingChildrenIfNeeded,
+		}
+
+		err := b.pathInForeground(path, path)
+
+		if err == zk.ErrNoNode {
+			err = nil
+		}
+
+		if err == nil || !isConnectionError(err) {
+			m.lock.Lock()
+
+			delete(m.pending, path)
+
+			m.lock.Unlock()
+		}
+
+		if m.listener != nil {
+			m.listener.PathRetriedForDelete(path, err)
+		}
+	}
+}
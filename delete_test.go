@@ -1,11 +1,15 @@
 package curator
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"sync"
 	"testing"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -13,6 +17,14 @@ type DeleteBuilderTestSuite struct {
 	mockContainerTestSuite
 }
 
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, isConnectionError(ErrConnectionLoss))
+	assert.True(t, isConnectionError(fmt.Errorf("delete /node: %w", zk.ErrSessionExpired)))
+	assert.True(t, isConnectionError(fmt.Errorf("dial: %w", &net.OpError{Op: "dial", Err: errors.New("refused")})))
+	assert.False(t, isConnectionError(zk.ErrNoNode))
+	assert.False(t, isConnectionError(fmt.Errorf("delete /node: %w", zk.ErrNotEmpty)))
+}
+
 func TestDeleteBuilder(t *testing.T) {
 	suite.Run(t, new(DeleteBuilderTestSuite))
 }
@@ -75,3 +87,86 @@ func (s *DeleteBuilderTestSuite) TestDeletingChildren() {
 		assert.NoError(s.T(), client.Delete().DeletingChildrenIfNeeded().ForPath("/parent"))
 	})
 }
+
+func (s *DeleteBuilderTestSuite) TestGuaranteed() {
+	listener := &mockFailedDeleteListener{log: s.T().Logf}
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.FailedDeleteListener = listener
+	}, func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, wg *sync.WaitGroup) {
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+		conn.On("Delete", "/node", AnyVersion).Return(zk.ErrConnectionClosed).Once()
+		conn.On("Delete", "/node", AnyVersion).Return(nil).Once()
+
+		listener.On("PathAddedForDelete", "/node", zk.ErrConnectionClosed).Return().Once()
+		listener.On("PathRetriedForDelete", "/node", nil).Return().Run(func(args mock.Arguments) {
+			wg.Done()
+		}).Once()
+
+		assert.EqualError(s.T(), client.Delete().Guaranteed().ForPath("/node"), zk.ErrConnectionClosed.Error())
+
+		client.(*curatorFramework).stateManager.AddStateChange(RECONNECTED)
+	})
+
+	listener.AssertExpectations(s.T())
+}
+
+func (s *DeleteBuilderTestSuite) TestGuaranteedFailedWhileRetrying() {
+	listener := &mockFailedDeleteListener{log: s.T().Logf}
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.FailedDeleteListener = listener
+	}, func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, wg *sync.WaitGroup) {
+		manager := client.(*curatorFramework).failedDeleteManager
+
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+		conn.On("Delete", "/node", AnyVersion).Return(zk.ErrConnectionClosed).Once()
+		conn.On("Delete", "/node", AnyVersion).Return(nil).Once()
+		conn.On("Delete", "/other", AnyVersion).Return(nil).Once()
+
+		listener.On("PathAddedForDelete", "/node", zk.ErrConnectionClosed).Return().Once()
+		listener.On("PathRetriedForDelete", "/node", nil).Return().Run(func(args mock.Arguments) {
+			// another delete fails and asks for a retry while the pass is running
+			manager.lock.Lock()
+			manager.pending["/other"] = failedDelete{version: AnyVersion}
+			manager.lock.Unlock()
+
+			manager.retryFailedDeletes()
+		}).Once()
+		listener.On("PathRetriedForDelete", "/other", nil).Return().Run(func(args mock.Arguments) {
+			wg.Done()
+		}).Once()
+
+		assert.EqualError(s.T(), client.Delete().Guaranteed().ForPath("/node"), zk.ErrConnectionClosed.Error())
+
+		client.(*curatorFramework).stateManager.AddStateChange(RECONNECTED)
+	})
+
+	listener.AssertExpectations(s.T())
+}
+
+func (s *DeleteBuilderTestSuite) TestGuaranteedNodeGone() {
+	listener := &mockFailedDeleteListener{log: s.T().Logf}
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.FailedDeleteListener = listener
+	}, func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, wg *sync.WaitGroup) {
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+		conn.On("Delete", "/node", AnyVersion).Return(zk.ErrConnectionClosed).Once()
+		conn.On("Delete", "/node", AnyVersion).Return(zk.ErrNoNode).Once()
+
+		listener.On("PathAddedForDelete", "/node", zk.ErrConnectionClosed).Return().Once()
+		listener.On("PathRetriedForDelete", "/node", nil).Return().Run(func(args mock.Arguments) {
+			wg.Done()
+		}).Once()
+
+		assert.EqualError(s.T(), client.Delete().Guaranteed().ForPath("/node"), zk.ErrConnectionClosed.Error())
+
+		client.(*curatorFramework).stateManager.AddStateChange(RECONNECTED)
+	})
+
+	listener.AssertExpectations(s.T())
+}
@@ -0,0 +1,384 @@
+package curator
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_DISPATCH_QUEUE_SIZE = 1024
+
+var (
+	ErrExecutorOverflow = errors.New("executor queue is full")
+	ErrExecutorClosed   = errors.New("executor has been closed")
+)
+
+// Executes the submitted tasks, such as the delivery of the events to the watchers and listeners
+type Executor interface {
+	// Submit the task, return an error if the task was not accepted
+	Execute(task func()) error
+}
+
+// Run the task through the executor, or in the current goroutine if the executor is nil
+func execute(executor Executor, task func()) error {
+	if executor == nil {
+		task()
+
+		return nil
+	}
+
+	return executor.Execute(task)
+}
+
+// The policy to apply when the queue of an executor is full
+type OverflowPolicy int32
+
+const (
+	OVERFLOW_BLOCK       OverflowPolicy = iota // block the caller until the queue has room
+	OVERFLOW_DROP_OLDEST                       // drop the oldest queued task to make room for the new one
+	OVERFLOW_REPORT                            // reject the new task and report ErrExecutorOverflow to the unhandled error listeners
+)
+
+// Executes the tasks one by one in the submitted order on a single goroutine, with a bounded queue
+type SerialExecutor struct {
+	tasks          chan func()
+	policy         OverflowPolicy
+	errorListeners UnhandledErrorListenable
+	closeOnce      sync.Once
+	closed         chan struct{}
+	dropped        int64
+}
+
+// Create a serial executor with the given queue size and overflow policy,
+// the overflow errors are reported to the errorListeners if it is not nil.
+func NewSerialExecutor(queueSize int, policy OverflowPolicy, errorListeners UnhandledErrorListenable) *SerialExecutor {
+	if queueSize <= 0 {
+		queueSize = DEFAULT_DISPATCH_QUEUE_SIZE
+	}
+
+	e := &SerialExecutor{
+		tasks:          make(chan func(), queueSize),
+		policy:         policy,
+		errorListeners: errorListeners,
+		closed:         make(chan struct{}),
+	}
+
+	go e.run()
+
+	return e
+}
+
+func (e *SerialExecutor) run() {
+	for {
+		select {
+		case <-e.closed:
+			return
+
+		case task := <-e.tasks:
+			task()
+		}
+	}
+}
+
+func (e *SerialExecutor) Execute(task func()) error {
+	select {
+	case <-e.closed:
+		return ErrExecutorClosed
+	default:
+	}
+
+	switch e.policy {
+	case OVERFLOW_DROP_OLDEST:
+		for {
+			select {
+			case e.tasks <- task:
+				return nil
+			default:
+			}
+
+			select {
+			case <-e.tasks:
+				atomic.AddInt64(&e.dropped, 1)
+			default:
+			}
+		}
+
+	case OVERFLOW_REPORT:
+		select {
+		case e.tasks <- task:
+			return nil
+		default:
+		}
+
+		atomic.AddInt64(&e.dropped, 1)
+
+		if e.errorListeners != nil {
+			e.errorListeners.ForEach(func(listener interface{}) {
+				notifyUnhandledError(DefaultLogger, listener, ErrExecutorOverflow)
+			})
+		}
+
+		return ErrExecutorOverflow
+
+	default:
+		select {
+		case e.tasks <- task:
+			return nil
+		case <-e.closed:
+			return ErrExecutorClosed
+		}
+	}
+}
+
+// Return the number of the queued tasks
+func (e *SerialExecutor) Len() int { return len(e.tasks) }
+
+// Return the number of the tasks dropped or rejected because the queue was full
+func (e *SerialExecutor) Dropped() int64 { return atomic.LoadInt64(&e.dropped) }
+
+// Stop the executor, the queued tasks will be discarded
+func (e *SerialExecutor) Close() {
+	e.closeOnce.Do(func() { close(e.closed) })
+}
+
+// The listener which is called through its own executor instead of the goroutine dispatching the events
+type executorListener struct {
+	listener interface{}
+	executor Executor
+	logger   Logger
+}
+
+func (l *executorListener) StateChanged(client CuratorFramework, newState ConnectionState) {
+	l.executor.Execute(func() {
+		info := CallbackError{Source: "ConnectionStateListener", Operation: "StateChanged", EventType: newState.String()}
+
+		l.errorHandler(client).call(info, func() error {
+			l.listener.(ConnectionStateListener).StateChanged(client, newState)
+
+			return nil
+		})
+	})
+}
+
+func (l *executorListener) EventReceived(client CuratorFramework, event CuratorEvent) error {
+	return l.executor.Execute(func() {
+		info := CallbackError{Source: "CuratorListener", Operation: "EventReceived", Path: event.Path(), EventType: event.Type().String()}
+
+		l.errorHandler(client).call(info, func() error {
+			return l.listener.(CuratorListener).EventReceived(client, event)
+		})
+	})
+}
+
+func (l *executorListener) UnhandledError(err error) {
+	l.executor.Execute(func() {
+		logger := l.logger
+
+		if logger == nil {
+			logger = DefaultLogger
+		}
+
+		notifyUnhandledError(logger, l.listener, err)
+	})
+}
+
+// Return the handler of the callback errors of the client,
+// which reports to the unhandled error listeners of the client if it is not created by the builder,
+// or only logs the errors if there is no client.
+func (l *executorListener) errorHandler(client CuratorFramework) *callbackErrorHandler {
+	if h := errorHandlerOf(client); h != nil {
+		return h
+	}
+
+	var listeners UnhandledErrorListenable = &unhandledErrorListenerContainer{}
+
+	if client != nil {
+		listeners = client.UnhandledErrorListenable()
+	}
+
+	return &callbackErrorHandler{listeners: listeners, logger: DefaultLogger}
+}
+
+// Return the original listener if it was wrapped for an executor
+func unwrapListener(listener interface{}) interface{} {
+	if l, ok := listener.(*executorListener); ok {
+		return l.listener
+	}
+
+	return listener
+}
+
+const (
+	DEFAULT_BACKGROUND_WORKERS    = 16
+	DEFAULT_BACKGROUND_QUEUE_SIZE = 1024
+)
+
+// Executes the tasks on a fixed number of goroutines with a bounded queue,
+// the callers are blocked when the queue is full.
+type WorkerPool struct {
+	lock    sync.Mutex
+	tasks   chan poolTask
+	closing bool
+	pending int            // the accepted tasks which have not finished
+	sending sync.WaitGroup // the accepted tasks waiting for room in the queue
+	drained chan struct{}  // closed when all the pending tasks finished after closing
+	stopped chan struct{}  // closed when the workers should exit
+	active  int64
+}
+
+type poolTask struct {
+	run     func()
+	abandon func() // called instead of run if the task is still queued when the pool is closed
+}
+
+// Create a worker pool with the given number of workers and queue size
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers <= 0 {
+		workers = DEFAULT_BACKGROUND_WORKERS
+	}
+
+	if queueSize <= 0 {
+		queueSize = DEFAULT_BACKGROUND_QUEUE_SIZE
+	}
+
+	p := &WorkerPool{
+		tasks:   make(chan poolTask, queueSize),
+		drained: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		go p.run()
+	}
+
+	return p
+}
+
+func (p *WorkerPool) run() {
+	for {
+		select {
+		case <-p.stopped:
+			return
+
+		case task := <-p.tasks:
+			p.execute(task.run)
+		}
+	}
+}
+
+func (p *WorkerPool) execute(task func()) {
+	atomic.AddInt64(&p.active, 1)
+
+	defer func() {
+		atomic.AddInt64(&p.active, -1)
+
+		p.done()
+	}()
+
+	task()
+}
+
+func (p *WorkerPool) done() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pending--
+
+	if p.closing && p.pending == 0 {
+		close(p.drained)
+	}
+}
+
+// Submit the task, block until the queue has room, return ErrExecutorClosed if the pool has been closed
+func (p *WorkerPool) Execute(task func()) error {
+	return p.ExecuteOrAbandon(task, nil)
+}
+
+// Submit the task like Execute, the abandon function is called instead of the task
+// if the task is still waiting for a worker when the pool is closed.
+func (p *WorkerPool) ExecuteOrAbandon(task func(), abandon func()) error {
+	p.lock.Lock()
+
+	if p.closing {
+		p.lock.Unlock()
+
+		return ErrExecutorClosed
+	}
+
+	p.pending++
+	p.sending.Add(1)
+
+	p.lock.Unlock()
+
+	defer p.sending.Done()
+
+	select {
+	case p.tasks <- poolTask{task, abandon}:
+		return nil
+
+	case <-p.stopped:
+		p.done()
+
+		return ErrExecutorClosed
+	}
+}
+
+// Return the number of the tasks waiting for a worker
+func (p *WorkerPool) Len() int { return len(p.tasks) }
+
+// Return the number of the tasks being executed
+func (p *WorkerPool) Active() int { return int(atomic.LoadInt64(&p.active)) }
+
+// Stop accepting the tasks and wait up to maxWait for the pending tasks to finish,
+// the tasks still waiting for a worker are abandoned, return the number of the tasks not finished.
+func (p *WorkerPool) Close(maxWait time.Duration) int {
+	p.lock.Lock()
+
+	if p.closing {
+		p.lock.Unlock()
+
+		return 0
+	}
+
+	p.closing = true
+
+	if p.pending == 0 {
+		close(p.drained)
+	}
+
+	p.lock.Unlock()
+
+	timer := time.NewTimer(maxWait)
+
+	defer timer.Stop()
+
+	select {
+	case <-p.drained:
+	case <-timer.C:
+	}
+
+	close(p.stopped)
+
+	p.sending.Wait() // the blocked submissions either queued their tasks or gave up
+
+	p.lock.Lock()
+
+	unfinished := p.pending
+
+	p.lock.Unlock()
+
+	for {
+		select {
+		case task := <-p.tasks:
+			if task.abandon != nil {
+				task.abandon()
+			}
+
+			p.done()
+
+		default:
+			return unfinished
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package curator
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerialExecutor(t *testing.T) {
+	e := NewSerialExecutor(4, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	var wg sync.WaitGroup
+	var results []int
+
+	for i := 0; i < 100; i++ {
+		i := i
+
+		wg.Add(1)
+
+		assert.NoError(t, e.Execute(func() {
+			defer wg.Done()
+
+			results = append(results, i)
+		}))
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 100, len(results))
+
+	for i, v := range results {
+		assert.Equal(t, i, v)
+	}
+
+	e.Close()
+
+	assert.Equal(t, ErrExecutorClosed, e.Execute(func() {}))
+}
+
+func TestSerialExecutorOverflow(t *testing.T) {
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	// drop the oldest tasks
+	e := NewSerialExecutor(2, OVERFLOW_DROP_OLDEST, nil)
+
+	defer e.Close()
+
+	var results []int
+
+	e.Execute(func() { close(started); <-block })
+
+	<-started
+
+	for i := 0; i < 5; i++ {
+		i := i
+
+		assert.NoError(t, e.Execute(func() { results = append(results, i) }))
+	}
+
+	assert.Equal(t, 2, e.Len())
+	assert.Equal(t, int64(3), e.Dropped())
+
+	done := make(chan struct{})
+
+	e.Execute(func() { close(done) })
+
+	close(block)
+
+	<-done
+
+	assert.Equal(t, []int{4}, results)
+
+	// report the overflow
+	var errs []error
+
+	listeners := &unhandledErrorListenerContainer{}
+
+	listeners.AddListener(NewUnhandledErrorListener(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	block = make(chan struct{})
+	started = make(chan struct{})
+
+	e = NewSerialExecutor(1, OVERFLOW_REPORT, listeners)
+
+	defer e.Close()
+
+	e.Execute(func() { close(started); <-block })
+
+	<-started
+
+	assert.NoError(t, e.Execute(func() {}))
+	assert.Equal(t, ErrExecutorOverflow, e.Execute(func() {}))
+	assert.Equal(t, []error{ErrExecutorOverflow}, errs)
+	assert.Equal(t, int64(1), e.Dropped())
+
+	close(block)
+}
+
+func TestFrameworkDispatcher(t *testing.T) {
+	client := (&CuratorFrameworkBuilder{
+		DispatchQueueSize:      1,
+		DispatchOverflowPolicy: OVERFLOW_REPORT,
+	}).ConnectString("connStr").Build().(*curatorFramework)
+
+	dispatcher := client.client.state.dispatcher
+
+	defer dispatcher.Close()
+
+	errs := make(chan error, 1)
+
+	client.UnhandledErrorListenable().AddListener(NewUnhandledErrorListener(func(err error) {
+		errs <- err
+	}))
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	defer close(block)
+
+	assert.NoError(t, dispatcher.Execute(func() { close(started); <-block }))
+
+	<-started
+
+	assert.NoError(t, dispatcher.Execute(func() {}))
+	assert.Equal(t, ErrExecutorOverflow, dispatcher.Execute(func() {}))
+	assert.Equal(t, ErrExecutorOverflow, <-errs)
+}
+
+func TestListenerWithExecutor(t *testing.T) {
+	e := NewSerialExecutor(0, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	c := make(chan ConnectionState, 1)
+
+	listeners := &connectionStateListenerContainer{}
+
+	listener := NewConnectionStateListener(func(client CuratorFramework, newState ConnectionState) {
+		c <- newState
+	})
+
+	listeners.AddListenerWithExecutor(listener, e)
+
+	assert.Equal(t, 1, listeners.Len())
+
+	listeners.ForEach(func(l interface{}) {
+		l.(ConnectionStateListener).StateChanged(nil, CONNECTED)
+	})
+
+	select {
+	case state := <-c:
+		assert.Equal(t, CONNECTED, state)
+	case <-time.After(time.Second):
+		assert.Fail(t, "listener not called")
+	}
+
+	listeners.RemoveListener(listener)
+
+	assert.Equal(t, 0, listeners.Len())
+}
+
+func TestWatchersWithExecutor(t *testing.T) {
+	e := NewSerialExecutor(0, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	var wg sync.WaitGroup
+	var paths []string
+
+	w := NewWatchersWithExecutor(e, NewWatcher(func(event *zk.Event) {
+		defer wg.Done()
+
+		paths = append(paths, event.Path)
+	}))
+
+	c := make(chan zk.Event)
+
+	go w.Watch(c)
+
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		wg.Add(1)
+
+		c <- zk.Event{Path: path}
+	}
+
+	close(c)
+
+	wg.Wait()
+
+	assert.Equal(t, []string{"/a", "/b", "/c", "/d"}, paths)
+}
+
+func TestWorkerPool(t *testing.T) {
+	p := NewWorkerPool(2, 1)
+
+	var wg sync.WaitGroup
+	var count int64
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		assert.NoError(t, p.Execute(func() {
+			defer wg.Done()
+
+			atomic.AddInt64(&count, 1)
+		}))
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(10), atomic.LoadInt64(&count))
+	assert.Equal(t, 0, p.Close(time.Second))
+	assert.Equal(t, ErrExecutorClosed, p.Execute(func() {}))
+}
+
+func TestWorkerPoolAbandoned(t *testing.T) {
+	p := NewWorkerPool(1, 2)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	defer close(block)
+
+	assert.NoError(t, p.Execute(func() { close(started); <-block }))
+
+	<-started
+
+	var abandoned int64
+
+	assert.NoError(t, p.Execute(func() {}))
+	assert.NoError(t, p.ExecuteOrAbandon(func() {
+		assert.Fail(t, "abandoned task should not run")
+	}, func() {
+		atomic.AddInt64(&abandoned, 1)
+	}))
+	assert.Equal(t, 2, p.Len())
+	assert.Equal(t, 1, p.Active())
+
+	// the running and the queued tasks are abandoned
+	assert.Equal(t, 3, p.Close(100*time.Millisecond))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&abandoned))
+	assert.Equal(t, 0, p.Len())
+	assert.Equal(t, ErrExecutorClosed, p.Execute(func() {}))
+}
@@ -161,5 +161,10 @@ Curator provides generic API for builder
 	    // Perform the action in the background
 	    InBackgroundWithCallbackAndContext(callback BackgroundCallback, context interface{}) T
 	}
+
+	type Contextual[T] interface {
+	    // Abort the operation when the given context is done
+	    WithContext(ctx context.Context) T
+	}
 */
 package curator
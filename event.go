@@ -9,20 +9,21 @@ import (
 type CuratorEventType int
 
 const (
-	CREATE   CuratorEventType = iota // CuratorFramework.Create() -> Err(), Path(), Data()
-	DELETE                           // CuratorFramework.Delete() -> Err(), Path()
-	EXISTS                           // CuratorFramework.CheckExists() -> Err(), Path(), Stat()
-	GET_DATA                         // CuratorFramework.GetData() -> Err(), Path(), Stat(), Data()
-	SET_DATA                         // CuratorFramework.SetData() -> Err(), Path(), Stat()
-	CHILDREN                         // CuratorFramework.GetChildren() -> Err(), Path(), Stat(), Children()
-	SYNC                             // CuratorFramework.Sync() -> Err(), Path()
-	GET_ACL                          // CuratorFramework.GetACL() -> Err(), Path()
-	SET_ACL                          // CuratorFramework.SetACL() -> Err(), Path()
-	WATCHED                          // Watchable.UsingWatcher() -> WatchedEvent()
-	CLOSING                          // Event sent when client is being closed
+	CREATE         CuratorEventType = iota // CuratorFramework.Create() -> Err(), Path(), Data()
+	DELETE                                 // CuratorFramework.Delete() -> Err(), Path()
+	EXISTS                                 // CuratorFramework.CheckExists() -> Err(), Path(), Stat()
+	GET_DATA                               // CuratorFramework.GetData() -> Err(), Path(), Stat(), Data()
+	SET_DATA                               // CuratorFramework.SetData() -> Err(), Path(), Stat()
+	CHILDREN                               // CuratorFramework.GetChildren() -> Err(), Path(), Stat(), Children()
+	SYNC                                   // CuratorFramework.Sync() -> Err(), Path()
+	GET_ACL                                // CuratorFramework.GetACL() -> Err(), Path()
+	SET_ACL                                // CuratorFramework.SetACL() -> Err(), Path()
+	WATCHED                                // Watchable.UsingWatcher() -> WatchedEvent()
+	CLOSING                                // Event sent when client is being closed
+	REMOVE_WATCHES                         // CuratorFramework.Watches().Remove() -> Err(), Path()
 )
 
-var CuratorEventTypeNames = []string{"CREATE", "DELETE", "EXISTS", "GET_DATA", "SET_DATA", "CHILDREN", "SYNC", "GET_ACL", "SET_ACL", "WATCHED", "CLOSING"}
+var CuratorEventTypeNames = []string{"CREATE", "DELETE", "EXISTS", "GET_DATA", "SET_DATA", "CHILDREN", "SYNC", "GET_ACL", "SET_ACL", "WATCHED", "CLOSING", "REMOVE_WATCHES"}
 
 func (t CuratorEventType) String() string {
 	if int(t) < len(CuratorEventTypeNames) {
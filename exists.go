@@ -1,20 +1,31 @@
 package curator
 
 import (
+	"context"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
 type checkExistsBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 	watching      watching
 }
 
 func (b *checkExistsBuilder) ForPath(givenPath string) (*zk.Stat, error) {
+	if b.watching.watched || b.watching.watcher != nil {
+		if err := b.client.schemaSet.ValidateWatch(givenPath); err != nil {
+			return nil, err
+		}
+	}
+
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath) }, b.backgrounding, EXISTS, givenPath); err != nil {
+			return nil, err
+		}
 
 		return nil, nil
 	} else {
@@ -39,45 +50,48 @@ func (b *checkExistsBuilder) pathInBackground(path string) {
 			context:   b.backgrounding.context,
 		}
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *checkExistsBuilder) pathInForeground(path string) (*zk.Stat, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "checkExists", Name: "checkExistsBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
 			var exists bool
-			var stat *zk.Stat
-			var events <-chan zk.Event
+			var res attemptResult
 			var err error
 
 			if b.watching.watched || b.watching.watcher != nil {
-				exists, stat, events, err = conn.ExistsW(path)
-
-				if events != nil && b.watching.watcher != nil {
-					go NewWatchers(b.watching.watcher).Watch(events)
-				}
+				exists, res.stat, res.events, err = conn.ExistsW(path)
 			} else {
-				exists, stat, err = conn.Exists(path)
+				exists, res.stat, err = conn.Exists(path)
 			}
 
 			if err != nil {
 				return nil, err
 			} else if !exists {
-				return nil, nil
-			} else {
-				return stat, nil
+				res.stat = nil
 			}
+
+			return &res, nil
 		}
 	})
 
-	stat, _ := result.(*zk.Stat)
+	res, _ := result.(*attemptResult)
+
+	if res == nil {
+		return nil, err
+	}
+
+	if res.events != nil && b.watching.watcher != nil {
+		b.client.client.state.watches.watch(path, WATCHER_DATA, b.watching.watcher, res.events)
+	}
 
-	return stat, err
+	return res.stat, err
 }
 
 func (b *checkExistsBuilder) Watched() CheckExistsBuilder {
@@ -87,7 +101,13 @@ func (b *checkExistsBuilder) Watched() CheckExistsBuilder {
 }
 
 func (b *checkExistsBuilder) UsingWatcher(watcher Watcher) CheckExistsBuilder {
-	b.watching.watcher = b.client.getNamespaceWatcher(watcher)
+	b.watching.watcher = b.client.wrapWatcher(watcher)
+
+	return b
+}
+
+func (b *checkExistsBuilder) WithContext(ctx context.Context) CheckExistsBuilder {
+	b.ctx = ctx
 
 	return b
 }
@@ -0,0 +1,284 @@
+// Package opentelemetry exports the operations and the events traced by the framework
+// as OpenTelemetry spans and metrics.
+//
+//	driver, err := opentelemetry.NewTracerDriver(tracerProvider, meterProvider)
+//
+//	builder := &curator.CuratorFrameworkBuilder{
+//		ConnectionTimeout: 1 * time.Second,
+//		SessionTimeout:    1 * time.Second,
+//		RetryPolicy:       curator.NewExponentialBackoffRetry(time.Second, 3, 15*time.Second),
+//		TracerDriver:      driver,
+//	}
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/flier/curator.go"
+)
+
+// The name of the instrumentation scope of the spans and the metrics
+const InstrumentationName = "github.com/flier/curator.go"
+
+// The prefix of the metric names
+const MetricPrefix = "curator."
+
+// The attributes of the spans and the metrics
+const (
+	AttrOperation     = attribute.Key("zk.operation")
+	AttrPath          = attribute.Key("zk.path")
+	AttrRetryCount    = attribute.Key("zk.retry_count")
+	AttrSessionId     = attribute.Key("zk.session_id")
+	AttrRequestBytes  = attribute.Key("zk.request_bytes")
+	AttrResponseBytes = attribute.Key("zk.response_bytes")
+	AttrError         = attribute.Key("zk.error")      // the error message, only on the spans
+	AttrErrorCode     = attribute.Key("zk.error_code") // the class of the error, such as "NoNode"
+	AttrEvent         = attribute.Key("zk.event")
+	AttrEventType     = attribute.Key("zk.event_type")
+	AttrState         = attribute.Key("zk.state")
+)
+
+// The classes of the errors reported on the metrics, the others are reported as "Other"
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{curator.ErrNoNode, "NoNode"},
+	{curator.ErrNodeExists, "NodeExists"},
+	{curator.ErrBadVersion, "BadVersion"},
+	{curator.ErrNotEmpty, "NotEmpty"},
+	{curator.ErrNoChildrenForEphemerals, "NoChildrenForEphemerals"},
+	{curator.ErrNoAuth, "NoAuth"},
+	{curator.ErrAuthFailed, "AuthFailed"},
+	{curator.ErrInvalidACL, "InvalidACL"},
+	{curator.ErrSessionExpired, "SessionExpired"},
+	{curator.ErrSessionMoved, "SessionMoved"},
+	{curator.ErrConnectionClosed, "ConnectionClosed"},
+	{curator.ErrConnectionLoss, "ConnectionLoss"},
+	{curator.ErrClosing, "Closing"},
+	{curator.ErrTimeout, "Timeout"},
+	{context.DeadlineExceeded, "DeadlineExceeded"},
+	{context.Canceled, "Canceled"},
+}
+
+// Return the class of the error, which keeps the cardinality of the metrics bounded
+func errorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	return "Other"
+}
+
+// The connection states reported by the connection state gauge
+var connectionStates = []curator.ConnectionState{
+	curator.CONNECTED, curator.SUSPENDED, curator.RECONNECTED, curator.LOST, curator.READ_ONLY,
+}
+
+var _ curator.AdvancedTracerDriver = (*TracerDriver)(nil)
+
+// TracerDriver which creates a span for every operation and records the metrics of the operations and the events
+type TracerDriver struct {
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	duration metric.Float64Histogram // the latency of the operations
+	retries  metric.Int64Counter     // the retries of the operations
+	events   metric.Int64Counter     // the watched events and the connection state changes
+
+	lock     sync.Mutex
+	state    string // the current connection state
+	counters map[string]metric.Int64Counter
+	timers   map[string]metric.Float64Histogram
+}
+
+// Create a TracerDriver with the given providers, or the global providers if nil
+func NewTracerDriver(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*TracerDriver, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	d := &TracerDriver{
+		tracer:   tracerProvider.Tracer(InstrumentationName),
+		meter:    meterProvider.Meter(InstrumentationName),
+		counters: make(map[string]metric.Int64Counter),
+		timers:   make(map[string]metric.Float64Histogram),
+	}
+
+	var err error
+
+	if d.duration, err = d.meter.Float64Histogram(MetricPrefix+"operation.duration",
+		metric.WithDescription("The latency of the ZooKeeper operations, including the retries"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	if d.retries, err = d.meter.Int64Counter(MetricPrefix+"operation.retries",
+		metric.WithDescription("The number of the retries of the ZooKeeper operations")); err != nil {
+		return nil, err
+	}
+
+	if d.events, err = d.meter.Int64Counter(MetricPrefix+"events",
+		metric.WithDescription("The number of the watched events and the connection state changes")); err != nil {
+		return nil, err
+	}
+
+	if _, err = d.meter.Int64ObservableGauge(MetricPrefix+"connection.state",
+		metric.WithDescription("1 for the current connection state, 0 for the others"),
+		metric.WithInt64Callback(d.observeState)); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *TracerDriver) observeState(ctx context.Context, observer metric.Int64Observer) error {
+	d.lock.Lock()
+	current := d.state
+	d.lock.Unlock()
+
+	if current == "" {
+		return nil
+	}
+
+	for _, state := range connectionStates {
+		var value int64
+
+		if state.String() == current {
+			value = 1
+		}
+
+		observer.Observe(value, metric.WithAttributes(AttrState.String(state.String())))
+	}
+
+	return nil
+}
+
+// Record the time with a histogram named after the trace
+func (d *TracerDriver) AddTime(name string, t time.Duration) {
+	d.lock.Lock()
+
+	timer, ok := d.timers[name]
+
+	if !ok {
+		timer, _ = d.meter.Float64Histogram(MetricPrefix+name, metric.WithUnit("s"))
+
+		d.timers[name] = timer
+	}
+
+	d.lock.Unlock()
+
+	if timer != nil {
+		timer.Record(context.Background(), t.Seconds())
+	}
+}
+
+// Add to a counter named after the trace, such as "session-expired" or "retries-allowed"
+func (d *TracerDriver) AddCount(name string, increment int) {
+	d.lock.Lock()
+
+	counter, ok := d.counters[name]
+
+	if !ok {
+		counter, _ = d.meter.Int64Counter(MetricPrefix + name)
+
+		d.counters[name] = counter
+	}
+
+	d.lock.Unlock()
+
+	if counter != nil {
+		counter.Add(context.Background(), int64(increment))
+	}
+}
+
+// Create a span for the operation and record its latency and retries
+func (d *TracerDriver) AddTrace(t *curator.OperationTrace) {
+	ctx := t.Context
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	op := t.Operation()
+	endTime := t.StartTime.Add(t.Latency)
+
+	attrs := []attribute.KeyValue{
+		AttrOperation.String(op),
+		AttrPath.String(t.Path),
+		AttrRetryCount.Int(t.RetryCount),
+		AttrSessionId.String(fmt.Sprintf("0x%x", t.SessionId)),
+		AttrRequestBytes.Int(t.RequestBytes),
+		AttrResponseBytes.Int(t.ResponseBytes),
+	}
+
+	if t.Err != nil {
+		attrs = append(attrs, AttrError.String(t.Err.Error()))
+	}
+
+	_, span := d.tracer.Start(ctx, "zookeeper."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(t.StartTime),
+		trace.WithAttributes(attrs...))
+
+	if t.Err != nil {
+		span.RecordError(t.Err, trace.WithTimestamp(endTime))
+		span.SetStatus(codes.Error, t.Err.Error())
+	}
+
+	span.End(trace.WithTimestamp(endTime))
+
+	metricAttrs := []attribute.KeyValue{AttrOperation.String(op)}
+
+	if t.Err != nil {
+		metricAttrs = append(metricAttrs, AttrErrorCode.String(errorCode(t.Err)))
+	}
+
+	d.duration.Record(ctx, t.Latency.Seconds(), metric.WithAttributes(metricAttrs...))
+
+	if t.RetryCount > 0 {
+		d.retries.Add(ctx, int64(t.RetryCount), metric.WithAttributes(AttrOperation.String(op)))
+	}
+}
+
+// Count the event and track the current connection state
+func (d *TracerDriver) AddEventTrace(t *curator.EventTrace) {
+	attrs := []attribute.KeyValue{AttrEvent.String(t.Name)}
+
+	if t.EventType != "" {
+		attrs = append(attrs, AttrEventType.String(t.EventType))
+	}
+
+	if t.State != "" {
+		attrs = append(attrs, AttrState.String(t.State))
+	}
+
+	if t.Name == "connection-state-change" {
+		d.lock.Lock()
+		d.state = t.State
+		d.lock.Unlock()
+	}
+
+	d.events.Add(context.Background(), 1, metric.WithAttributes(attrs...))
+}
+
+// Return the operation of a trace, such as "getData" for "getDataBuilder.pathInForeground"
+func OperationName(name string) string {
+	return (&curator.OperationTrace{Name: name}).Operation()
+}
@@ -0,0 +1,189 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/flier/curator.go"
+)
+
+func newTestDriver(t *testing.T) (*TracerDriver, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	d, err := NewTracerDriver(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	assert.NoError(t, err)
+
+	return d, spans, reader
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	return metrics
+}
+
+func TestOperationName(t *testing.T) {
+	assert.Equal(t, "getData", OperationName("getDataBuilder.pathInForeground"))
+	assert.Equal(t, "checkExists", OperationName("checkExistsBuilder.pathInForeground"))
+	assert.Equal(t, "curatorTransaction.commit", OperationName("curatorTransaction.commit"))
+	assert.Equal(t, "retry-loop", OperationName("retry-loop"))
+}
+
+func TestOperationSpan(t *testing.T) {
+	d, spans, reader := newTestDriver(t)
+
+	parent, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+
+	defer span.End()
+
+	start := time.Now()
+
+	d.AddTrace(&curator.OperationTrace{
+		Context:       parent,
+		Name:          "getDataBuilder.pathInForeground",
+		Path:          "/node",
+		ResponseBytes: 4,
+		StartTime:     start,
+		Latency:       time.Second,
+		SessionId:     0x123,
+		RetryCount:    2,
+	})
+
+	d.AddTrace(&curator.OperationTrace{
+		Name:      "deleteBuilder.pathInForeground",
+		Path:      "/node",
+		StartTime: start,
+		Latency:   time.Millisecond,
+		Err:       zk.ErrNoNode,
+	})
+
+	ended := spans.Ended()
+
+	if assert.Equal(t, 2, len(ended)) {
+		s := ended[0]
+
+		assert.Equal(t, "zookeeper.getData", s.Name())
+		assert.Equal(t, trace.SpanKindClient, s.SpanKind())
+		assert.Equal(t, span.SpanContext().TraceID(), s.SpanContext().TraceID())
+		assert.Equal(t, span.SpanContext().SpanID(), s.Parent().SpanID())
+		assert.Equal(t, start, s.StartTime())
+		assert.Equal(t, start.Add(time.Second), s.EndTime())
+		assert.Contains(t, s.Attributes(), AttrPath.String("/node"))
+		assert.Contains(t, s.Attributes(), AttrRetryCount.Int(2))
+		assert.Contains(t, s.Attributes(), AttrSessionId.String("0x123"))
+		assert.Contains(t, s.Attributes(), AttrResponseBytes.Int(4))
+		assert.Equal(t, codes.Unset, s.Status().Code)
+
+		s = ended[1]
+
+		assert.Equal(t, "zookeeper.delete", s.Name())
+		assert.False(t, s.Parent().IsValid())
+		assert.Contains(t, s.Attributes(), AttrError.String(zk.ErrNoNode.Error()))
+		assert.Equal(t, codes.Error, s.Status().Code)
+		assert.Equal(t, 1, len(s.Events()))
+	}
+
+	metrics := collect(t, reader)
+
+	if duration, ok := metrics["curator.operation.duration"].(metricdata.Histogram[float64]); assert.True(t, ok) {
+		assert.Equal(t, 2, len(duration.DataPoints))
+
+		for _, dp := range duration.DataPoints {
+			op, _ := dp.Attributes.Value(AttrOperation)
+
+			switch op.AsString() {
+			case "getData":
+				assert.Equal(t, 1.0, dp.Sum)
+				assert.False(t, dp.Attributes.HasValue(AttrErrorCode))
+			case "delete":
+				assert.Equal(t, 0.001, dp.Sum)
+				assert.False(t, dp.Attributes.HasValue(AttrError))
+
+				code, _ := dp.Attributes.Value(AttrErrorCode)
+
+				assert.Equal(t, "NoNode", code.AsString())
+			default:
+				assert.Fail(t, "unexpected operation", op.AsString())
+			}
+		}
+	}
+
+	if retries, ok := metrics["curator.operation.retries"].(metricdata.Sum[int64]); assert.True(t, ok) {
+		if assert.Equal(t, 1, len(retries.DataPoints)) {
+			assert.Equal(t, int64(2), retries.DataPoints[0].Value)
+			assert.Equal(t, attribute.NewSet(AttrOperation.String("getData")), retries.DataPoints[0].Attributes)
+		}
+	}
+}
+
+func TestEventMetrics(t *testing.T) {
+	d, _, reader := newTestDriver(t)
+
+	metrics := collect(t, reader)
+
+	assert.NotContains(t, metrics, "curator.connection.state")
+
+	d.AddEventTrace(&curator.EventTrace{Name: "connection-state-change", State: curator.CONNECTED.String()})
+	d.AddEventTrace(&curator.EventTrace{Name: "connection-state-change", State: curator.SUSPENDED.String()})
+	d.AddEventTrace(&curator.EventTrace{Name: "watched-event", Path: "/node", EventType: zk.EventNodeCreated.String()})
+	d.AddCount("session-expired", 1)
+	d.AddCount("session-expired", 2)
+	d.AddTime("connection-state-parent-process", time.Second)
+
+	metrics = collect(t, reader)
+
+	if state, ok := metrics["curator.connection.state"].(metricdata.Gauge[int64]); assert.True(t, ok) {
+		assert.Equal(t, len(connectionStates), len(state.DataPoints))
+
+		for _, dp := range state.DataPoints {
+			s, _ := dp.Attributes.Value(AttrState)
+
+			if s.AsString() == curator.SUSPENDED.String() {
+				assert.Equal(t, int64(1), dp.Value)
+			} else {
+				assert.Equal(t, int64(0), dp.Value)
+			}
+		}
+	}
+
+	if events, ok := metrics["curator.events"].(metricdata.Sum[int64]); assert.True(t, ok) {
+		assert.Equal(t, 3, len(events.DataPoints))
+	}
+
+	if expired, ok := metrics["curator.session-expired"].(metricdata.Sum[int64]); assert.True(t, ok) {
+		if assert.Equal(t, 1, len(expired.DataPoints)) {
+			assert.Equal(t, int64(3), expired.DataPoints[0].Value)
+		}
+	}
+
+	if timer, ok := metrics["curator.connection-state-parent-process"].(metricdata.Histogram[float64]); assert.True(t, ok) {
+		if assert.Equal(t, 1, len(timer.DataPoints)) {
+			assert.Equal(t, 1.0, timer.DataPoints[0].Sum)
+		}
+	}
+}
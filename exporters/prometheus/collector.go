@@ -0,0 +1,235 @@
+// Package prometheus exposes the metrics of the framework and its recipes as a Prometheus collector.
+//
+//	collector := prometheus.NewCollector()
+//
+//	registry.MustRegister(collector)
+//
+//	builder := &curator.CuratorFrameworkBuilder{
+//		ConnectionTimeout: 1 * time.Second,
+//		SessionTimeout:    1 * time.Second,
+//		RetryPolicy:       curator.NewExponentialBackoffRetry(time.Second, 3, 15*time.Second),
+//		MetricsCollector:  collector,
+//	}
+package prometheus
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/flier/curator.go"
+)
+
+// The namespace of the metric names
+const Namespace = "curator"
+
+// The connection states reported by the connection state gauge
+var connectionStates = []curator.ConnectionState{
+	curator.CONNECTED, curator.SUSPENDED, curator.RECONNECTED, curator.LOST, curator.READ_ONLY,
+}
+
+var _ curator.MetricsCollector = (*Collector)(nil)
+
+// Collector of the metrics of the framework and its recipes, which should be set as the MetricsCollector of the builder
+type Collector struct {
+	lock   sync.Mutex
+	client curator.CuratorFramework // the framework bound to the collector
+	state  string                   // the current connection state
+
+	operations    *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+	counters      *prometheus.CounterVec
+	timers        *prometheus.HistogramVec
+	watchedEvents *prometheus.CounterVec
+	reconnects    prometheus.Counter
+	expirations   prometheus.Counter
+	lockWait      *prometheus.HistogramVec
+	locksHeld     *prometheus.GaugeVec
+	cacheSize     *prometheus.GaugeVec
+
+	stateDesc    *prometheus.Desc
+	queueDesc    *prometheus.Desc
+	watchersDesc *prometheus.Desc
+}
+
+// Create a collector which is registered to a Prometheus registry and set as the MetricsCollector of the builder
+func NewCollector() *Collector {
+	return &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "operations_total",
+			Help:      "The number of the ZooKeeper operations.",
+		}, []string{"operation", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "The latency of the ZooKeeper operations, including the retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "operation_retries_total",
+			Help:      "The number of the retries of the ZooKeeper operations.",
+		}, []string{"operation"}),
+		counters: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "counters_total",
+			Help:      "The counters added by the tracer, such as session-expired or retries-allowed.",
+		}, []string{"name"}),
+		timers: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "timers_seconds",
+			Help:      "The times recorded by the tracer.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"name"}),
+		watchedEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "watched_events_total",
+			Help:      "The number of the events delivered to the watchers.",
+		}, []string{"type"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "reconnects_total",
+			Help:      "The number of the times the connection has been re-established.",
+		}),
+		expirations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "session_expirations_total",
+			Help:      "The number of the times the session has expired.",
+		}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "lock_wait_seconds",
+			Help:      "The time spent waiting for the locks.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"path", "acquired"}),
+		locksHeld: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "locks_held",
+			Help:      "The number of the locks held by this process.",
+		}, []string{"path"}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "cache_size",
+			Help:      "The number of the nodes held by the caches.",
+		}, []string{"path"}),
+		stateDesc: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "connection_state"),
+			"1 for the current connection state, 0 for the others.", []string{"state"}, nil),
+		queueDesc: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "background_queue_length"),
+			"The number of the background operations waiting for a worker.", nil, nil),
+		watchersDesc: prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "active_watchers"),
+			"The number of the watchers waiting for their events.", nil, nil),
+	}
+}
+
+func (c *Collector) vectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.operations, c.latency, c.retries, c.counters, c.timers, c.watchedEvents,
+		c.reconnects, c.expirations, c.lockWait, c.locksHeld, c.cacheSize,
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, v := range c.vectors() {
+		v.Describe(ch)
+	}
+
+	ch <- c.stateDesc
+	ch <- c.queueDesc
+	ch <- c.watchersDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, v := range c.vectors() {
+		v.Collect(ch)
+	}
+
+	c.lock.Lock()
+	client := c.client
+	current := c.state
+	c.lock.Unlock()
+
+	if current != "" {
+		for _, state := range connectionStates {
+			var value float64
+
+			if state.String() == current {
+				value = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(c.stateDesc, prometheus.GaugeValue, value, state.String())
+		}
+	}
+
+	if client != nil {
+		ch <- prometheus.MustNewConstMetric(c.queueDesc, prometheus.GaugeValue, float64(client.BackgroundQueueLen()))
+		ch <- prometheus.MustNewConstMetric(c.watchersDesc, prometheus.GaugeValue, float64(client.ActiveWatchers()))
+	}
+}
+
+// Bind the framework to sample its background queue and watchers
+func (c *Collector) Bind(client curator.CuratorFramework) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.client = client
+}
+
+func (c *Collector) AddTime(name string, d time.Duration) {
+	c.timers.WithLabelValues(name).Observe(d.Seconds())
+}
+
+func (c *Collector) AddCount(name string, increment int) {
+	c.counters.WithLabelValues(name).Add(float64(increment))
+
+	if name == "session-expired" {
+		c.expirations.Add(float64(increment))
+	}
+}
+
+func (c *Collector) AddTrace(trace *curator.OperationTrace) {
+	op := trace.Operation()
+	result := "success"
+
+	if trace.Err != nil {
+		result = "error"
+	}
+
+	c.operations.WithLabelValues(op, result).Inc()
+	c.latency.WithLabelValues(op).Observe(trace.Latency.Seconds())
+
+	if trace.RetryCount > 0 {
+		c.retries.WithLabelValues(op).Add(float64(trace.RetryCount))
+	}
+}
+
+func (c *Collector) AddEventTrace(trace *curator.EventTrace) {
+	switch trace.Name {
+	case "watched-event":
+		c.watchedEvents.WithLabelValues(trace.EventType).Inc()
+
+	case "connection-state-change":
+		c.lock.Lock()
+		c.state = trace.State
+		c.lock.Unlock()
+
+		if trace.State == curator.RECONNECTED.String() {
+			c.reconnects.Inc()
+		}
+	}
+}
+
+func (c *Collector) ObserveLockWait(path string, d time.Duration, acquired bool) {
+	c.lockWait.WithLabelValues(path, strconv.FormatBool(acquired)).Observe(d.Seconds())
+}
+
+func (c *Collector) AddLocksHeld(path string, delta int) {
+	c.locksHeld.WithLabelValues(path).Add(float64(delta))
+}
+
+func (c *Collector) SetCacheSize(path string, size int) {
+	c.cacheSize.WithLabelValues(path).Set(float64(size))
+}
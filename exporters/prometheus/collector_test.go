@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/flier/curator.go"
+)
+
+type fakeFramework struct {
+	curator.CuratorFramework
+
+	queueLen int
+	watchers int
+}
+
+func (f *fakeFramework) BackgroundQueueLen() int { return f.queueLen }
+func (f *fakeFramework) ActiveWatchers() int     { return f.watchers }
+
+func TestCollectorRegister(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+
+	assert.NoError(t, registry.Register(NewCollector()))
+}
+
+func TestOperationMetrics(t *testing.T) {
+	c := NewCollector()
+
+	c.AddTrace(&curator.OperationTrace{Name: "getDataBuilder.pathInForeground", Latency: time.Second, RetryCount: 2})
+	c.AddTrace(&curator.OperationTrace{Name: "getDataBuilder.pathInForeground", Latency: time.Second})
+	c.AddTrace(&curator.OperationTrace{Name: "deleteBuilder.pathInForeground", Err: zk.ErrNoNode})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.operations.WithLabelValues("getData", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.operations.WithLabelValues("delete", "error")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.retries.WithLabelValues("getData")))
+	assert.Equal(t, 2, testutil.CollectAndCount(c.latency))
+
+	c.AddCount("session-expired", 1)
+	c.AddCount("retries-allowed", 3)
+	c.AddTime("connection-state-parent-process", time.Second)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.expirations))
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.counters.WithLabelValues("retries-allowed")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.timers))
+}
+
+func TestStateMetrics(t *testing.T) {
+	c := NewCollector()
+
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "curator_connection_state", "curator_background_queue_length"))
+
+	c.Bind(&fakeFramework{queueLen: 3, watchers: 5})
+
+	c.AddEventTrace(&curator.EventTrace{Name: "connection-state-change", State: curator.CONNECTED.String()})
+	c.AddEventTrace(&curator.EventTrace{Name: "connection-state-change", State: curator.SUSPENDED.String()})
+	c.AddEventTrace(&curator.EventTrace{Name: "connection-state-change", State: curator.RECONNECTED.String()})
+	c.AddEventTrace(&curator.EventTrace{Name: "watched-event", Path: "/node", EventType: zk.EventNodeCreated.String()})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.reconnects))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.watchedEvents.WithLabelValues(zk.EventNodeCreated.String())))
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP curator_active_watchers The number of the watchers waiting for their events.
+# TYPE curator_active_watchers gauge
+curator_active_watchers 5
+# HELP curator_background_queue_length The number of the background operations waiting for a worker.
+# TYPE curator_background_queue_length gauge
+curator_background_queue_length 3
+# HELP curator_connection_state 1 for the current connection state, 0 for the others.
+# TYPE curator_connection_state gauge
+curator_connection_state{state="CONNECTED"} 0
+curator_connection_state{state="LOST"} 0
+curator_connection_state{state="READ_ONLY"} 0
+curator_connection_state{state="RECONNECTED"} 1
+curator_connection_state{state="SUSPENDED"} 0
+`), "curator_active_watchers", "curator_background_queue_length", "curator_connection_state"))
+}
+
+func TestRecipeMetrics(t *testing.T) {
+	c := NewCollector()
+
+	c.ObserveLockWait("/lock", time.Second, true)
+	c.ObserveLockWait("/lock", time.Second, false)
+	c.AddLocksHeld("/lock", 1)
+	c.AddLocksHeld("/lock", 1)
+	c.AddLocksHeld("/lock", -1)
+	c.SetCacheSize("/node", 1)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(c.lockWait))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.locksHeld.WithLabelValues("/lock")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.cacheSize.WithLabelValues("/node")))
+}
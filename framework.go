@@ -1,8 +1,8 @@
 package curator
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -56,9 +56,18 @@ type CuratorFramework interface {
 	// Start a transaction builder
 	InTransaction() Transaction
 
+	// Start an update builder to read, modify and write the nodes with optimistic concurrency
+	Update() UpdateBuilder
+
 	// Perform a sync on the given path - syncs are always in the background
 	DoSync(path string, backgroundContextObject interface{})
 
+	// Return the asynchronous facade of the current instance
+	Async() AsyncCuratorFramework
+
+	// Start a builder to add or remove the persistent watches
+	Watches() WatchesBuilder
+
 	//  Start a sync builder. Note: sync is ALWAYS in the background even if you don't use one of the background() methods
 	Sync() SyncBuilder
 
@@ -84,6 +93,27 @@ type CuratorFramework interface {
 	// Return the managed zookeeper client
 	ZookeeperClient() CuratorZookeeperClient
 
+	// Return the serializer used to read and write the values
+	Serializer() Serializer
+
+	// Return the schemas to validate the operations, or nil if not set
+	SchemaSet() *SchemaSet
+
+	// Return the policy to decide which connection states are errors
+	ConnectionStateErrorPolicy() ConnectionStateErrorPolicy
+
+	// Return the number of background operations waiting for a worker
+	BackgroundQueueLen() int
+
+	// Return the logger of the client
+	Logger() Logger
+
+	// Return the number of the watchers waiting for their events
+	ActiveWatchers() int
+
+	// Return the recorder of the metrics reported by the recipes
+	RecipeMetrics() RecipeMetrics
+
 	// Allocates an ensure path instance that is namespace aware
 	NewNamespaceAwareEnsurePath(path string) EnsurePath
 
@@ -92,6 +122,9 @@ type CuratorFramework interface {
 
 	// Block until a connection to ZooKeeper is available or the maxWaitTime has been exceeded
 	BlockUntilConnectedTimeout(maxWaitTime time.Duration) error
+
+	// Block until a connection to ZooKeeper is available or the context is done
+	BlockUntilConnectedWithContext(ctx context.Context) error
 }
 
 // Create a new client with default session timeout and default connection timeout
@@ -111,18 +144,35 @@ func NewClientTimeout(connString string, sessionTimeout, connectionTimeout time.
 }
 
 type CuratorFrameworkBuilder struct {
-	AuthInfos           []AuthInfo          // the connection authorization
-	ZookeeperDialer     ZookeeperDialer     // the zookeeper dialer to use
-	EnsembleProvider    EnsembleProvider    // the list ensemble provider.
-	DefaultData         []byte              // the data to use when PathAndBytesable.ForPath(String) is used.
-	Namespace           string              // as ZooKeeper is a shared space, users of a given cluster should stay within a pre-defined namespace
-	SessionTimeout      time.Duration       // the session timeout
-	ConnectionTimeout   time.Duration       // the connection timeout
-	MaxCloseWait        time.Duration       // the time to wait during close to wait background tasks
-	RetryPolicy         RetryPolicy         // the retry policy to use
-	CompressionProvider CompressionProvider // the compression provider
-	AclProvider         ACLProvider         // the provider for ACLs
-	CanBeReadOnly       bool                // allow ZooKeeper client to enter read only mode in case of a network partition.
+	AuthInfos            []AuthInfo           // the connection authorization
+	ZookeeperDialer      ZookeeperDialer      // the zookeeper dialer to use
+	EnsembleProvider     EnsembleProvider     // the list ensemble provider.
+	DefaultData          []byte               // the data to use when PathAndBytesable.ForPath(String) is used.
+	Namespace            string               // as ZooKeeper is a shared space, users of a given cluster should stay within a pre-defined namespace
+	SessionTimeout       time.Duration        // the session timeout
+	ConnectionTimeout    time.Duration        // the connection timeout
+	MaxCloseWait         time.Duration        // the time to wait during close to wait background tasks
+	BackgroundWorkers    int                  // the number of goroutines to run the background operations
+	BackgroundQueueSize  int                  // the number of background operations queued before the callers are blocked
+	DispatchQueueSize    int                  // the number of events queued for the watchers and listeners, default to DEFAULT_DISPATCH_QUEUE_SIZE
+	CallbackErrorMode    CallbackErrorMode    // how to handle the errors and panics of the callbacks, listeners and watchers
+	Logger               Logger               // the logger of the client and its recipes, default to the DefaultLogger
+	TracerDriver         TracerDriver         // the tracer driver, which is adapted to receive the traces of the operations and events unless it is an AdvancedTracerDriver
+	MetricsCollector     MetricsCollector     // the collector of the metrics of the framework and its recipes
+	RetryPolicy          RetryPolicy          // the retry policy to use
+	RetryClassifier      RetryClassifier      // decide which errors are retryable, default to the StandardRetryClassifier
+	CompressionProvider  CompressionProvider  // the compression provider
+	AclProvider          ACLProvider          // the provider for ACLs
+	CanBeReadOnly        bool                 // allow ZooKeeper client to enter read only mode in case of a network partition.
+	FailedDeleteListener FailedDeleteListener // the listener to observe the failed guaranteed deletes
+	Serializer           Serializer           // the serializer used to read and write the values
+	SchemaSet            *SchemaSet           // the schemas to validate the operations
+	// the policy to decide which connection states are errors, default to the StandardConnectionStateErrorPolicy
+	ConnectionStateErrorPolicy ConnectionStateErrorPolicy
+	// the percent of the negotiated session timeout to wait in SUSPENDED before emulating LOST, negative to disable
+	SessionExpirationPercent int
+	// the policy to apply when the dispatch queue is full, default to OVERFLOW_BLOCK which blocks the connection
+	DispatchOverflowPolicy OverflowPolicy
 }
 
 // Apply the current values and build a new CuratorFramework
@@ -142,14 +192,59 @@ func (b *CuratorFrameworkBuilder) Build() CuratorFramework {
 	if builder.MaxCloseWait == 0 {
 		builder.MaxCloseWait = DEFAULT_CLOSE_WAIT
 	}
+	if builder.BackgroundWorkers == 0 {
+		builder.BackgroundWorkers = DEFAULT_BACKGROUND_WORKERS
+	}
+	if builder.BackgroundQueueSize == 0 {
+		builder.BackgroundQueueSize = DEFAULT_BACKGROUND_QUEUE_SIZE
+	}
+	if builder.DispatchQueueSize == 0 {
+		builder.DispatchQueueSize = DEFAULT_DISPATCH_QUEUE_SIZE
+	}
 	if builder.CompressionProvider == nil {
 		builder.CompressionProvider = NewGzipCompressionProvider()
 	}
 	if builder.AclProvider == nil {
 		builder.AclProvider = NewDefaultACLProvider()
 	}
+	if builder.Serializer == nil {
+		builder.Serializer = NewJsonSerializer()
+	}
+	if builder.Logger == nil {
+		builder.Logger = DefaultLogger
+	}
+	if builder.RetryClassifier == nil {
+		builder.RetryClassifier = NewStandardRetryClassifier()
+	}
+	if builder.ConnectionStateErrorPolicy == nil {
+		builder.ConnectionStateErrorPolicy = NewStandardConnectionStateErrorPolicy()
+	}
+	if builder.SessionExpirationPercent == 0 {
+		builder.SessionExpirationPercent = DEFAULT_SESSION_EXPIRATION_PERCENT
+	} else if builder.SessionExpirationPercent > 100 {
+		builder.Logger.Warn("Session expiration percent is greater than 100, using 100 instead",
+			"sessionExpirationPercent", builder.SessionExpirationPercent)
+
+		builder.SessionExpirationPercent = 100
+	}
+	if builder.TracerDriver != nil {
+		builder.TracerDriver = NewAdvancedTracerDriverAdapter(builder.TracerDriver)
+	}
+	if builder.MetricsCollector != nil {
+		if builder.TracerDriver == nil {
+			builder.TracerDriver = builder.MetricsCollector
+		} else {
+			builder.TracerDriver = multiTracerDriver{builder.TracerDriver, builder.MetricsCollector}
+		}
+	}
 
-	return newCuratorFramework(&builder)
+	c := newCuratorFramework(&builder)
+
+	if builder.MetricsCollector != nil {
+		builder.MetricsCollector.Bind(c)
+	}
+
+	return c
 }
 
 // Set the list of servers to connect to.
@@ -175,12 +270,23 @@ func (b *CuratorFrameworkBuilder) Compression(name string) *CuratorFrameworkBuil
 	return b
 }
 
+// Set the serializer
+func (b *CuratorFrameworkBuilder) Serialization(name string) *CuratorFrameworkBuilder {
+	if serializer, exists := Serializers[name]; exists {
+		b.Serializer = serializer
+	}
+
+	return b
+}
+
 type curatorFramework struct {
 	client                  *curatorZookeeperClient
 	stateManager            *connectionStateManager
 	state                   State
 	listeners               CuratorListenable
 	unhandledErrorListeners UnhandledErrorListenable
+	errorHandler            *callbackErrorHandler
+	logger                  Logger
 	defaultData             []byte
 	namespace               *namespaceImpl
 	namespaceFacadeCache    *namespaceFacadeCache
@@ -189,18 +295,40 @@ type curatorFramework struct {
 	retryPolicy             RetryPolicy
 	compressionProvider     CompressionProvider
 	aclProvider             ACLProvider
+	serializer              Serializer
+	schemaSet               *SchemaSet
+	stateErrorPolicy        ConnectionStateErrorPolicy
+	backgroundPool          *WorkerPool
+	maxCloseWait            time.Duration
+	recipeMetrics           RecipeMetrics
+	failedDeleteManager     *failedDeleteManager
+	failedRemoveWatches     *failedRemoveWatchesManager
 }
 
 func newCuratorFramework(b *CuratorFrameworkBuilder) *curatorFramework {
 	c := &curatorFramework{
 		listeners:               &curatorListenerContainer{},
-		unhandledErrorListeners: &unhandledErrorListenerContainer{},
+		unhandledErrorListeners: &unhandledErrorListenerContainer{logger: b.Logger},
 		defaultData:             b.DefaultData,
 		retryPolicy:             b.RetryPolicy,
 		compressionProvider:     b.CompressionProvider,
 		aclProvider:             b.AclProvider,
+		serializer:              b.Serializer,
+		schemaSet:               b.SchemaSet,
+		stateErrorPolicy:        b.ConnectionStateErrorPolicy,
+		backgroundPool:          NewWorkerPool(b.BackgroundWorkers, b.BackgroundQueueSize),
+		maxCloseWait:            b.MaxCloseWait,
+		logger:                  b.Logger,
+	}
+
+	if b.MetricsCollector != nil {
+		c.recipeMetrics = b.MetricsCollector
+	} else {
+		c.recipeMetrics = nopRecipeMetrics{}
 	}
 
+	c.errorHandler = &callbackErrorHandler{listeners: c.unhandledErrorListeners, mode: b.CallbackErrorMode, logger: b.Logger}
+
 	watcher := NewWatcher(func(event *zk.Event) {
 		c.processEvent(&curatorEvent{
 			eventType:    WATCHED,
@@ -208,10 +336,34 @@ func newCuratorFramework(b *CuratorFrameworkBuilder) *curatorFramework {
 			path:         c.unfixForNamespace(event.Path),
 			watchedEvent: event,
 		})
+
+		c.namespaceFacadeCache.processEvent(event)
 	})
 
-	c.client = NewCuratorZookeeperClient(b.ZookeeperDialer, b.EnsembleProvider, b.SessionTimeout, b.ConnectionTimeout, watcher, b.RetryPolicy, b.CanBeReadOnly, b.AuthInfos)
+	// the overflow of the events is reported to the unhandled error listeners of the framework
+	dispatcher := NewSerialExecutor(b.DispatchQueueSize, b.DispatchOverflowPolicy, c.unhandledErrorListeners)
+
+	c.client = newCuratorZookeeperClient(b.ZookeeperDialer, b.EnsembleProvider, b.SessionTimeout, b.ConnectionTimeout, watcher, b.RetryPolicy, b.CanBeReadOnly, b.AuthInfos, b.Logger, dispatcher)
+	if b.TracerDriver != nil {
+		c.client.TracerDriver = b.TracerDriver
+		c.client.state.tracer = b.TracerDriver
+	}
+	c.client.classifier = b.RetryClassifier
+
 	c.stateManager = newConnectionStateManager(c)
+	c.stateManager.tracer = c.client.TracerDriver
+	c.stateManager.errorHandler = c.errorHandler
+	c.stateManager.logger = b.Logger
+	c.stateManager.SessionExpirationPercent = b.SessionExpirationPercent
+	c.stateManager.sessionTimeout = c.client.state.negotiatedSessionTimeout
+	c.stateManager.expireSession = c.client.state.expireSession
+	c.failedDeleteManager = newFailedDeleteManager(c, b.FailedDeleteListener)
+	c.stateManager.Listenable().AddListener(c.failedDeleteManager)
+	c.failedRemoveWatches = newFailedRemoveWatchesManager(c)
+	c.stateManager.Listenable().AddListener(c.failedRemoveWatches)
+	if listener, ok := b.RetryPolicy.(ConnectionStateListener); ok {
+		c.stateManager.Listenable().AddListener(listener)
+	}
 	c.namespace = newNamespace(c, b.Namespace)
 	c.namespaceFacadeCache = newNamespaceFacadeCache(c)
 	c.fixForNamespace = c.namespace.fixForNamespace
@@ -237,13 +389,18 @@ func (c *curatorFramework) Close() error {
 		return nil
 	}
 
+	if abandoned := c.backgroundPool.Close(c.maxCloseWait); abandoned > 0 {
+		c.logError(fmt.Errorf("%d background operations abandoned after waiting %v", abandoned, c.maxCloseWait))
+	}
+
 	evt := &curatorEvent{eventType: CLOSING}
 
 	c.listeners.ForEach(func(listener interface{}) {
-		listener.(CuratorListener).EventReceived(c, evt)
+		c.callListener(listener.(CuratorListener), evt)
 	})
 
 	c.listeners.Clear()
+	c.namespaceFacadeCache.close(evt)
 	c.unhandledErrorListeners.Clear()
 	c.stateManager.Close()
 
@@ -316,12 +473,28 @@ func (c *curatorFramework) DoSync(path string, context interface{}) {
 	c.Sync().InBackgroundWithContext(context).ForPath(path)
 }
 
+func (c *curatorFramework) Update() UpdateBuilder {
+	c.state.Check(STARTED, "instance must be started before calling this method")
+
+	return &updateBuilder{client: c}
+}
+
 func (c *curatorFramework) Sync() SyncBuilder {
 	c.state.Check(STARTED, "instance must be started before calling this method")
 
 	return &syncBuilder{client: c}
 }
 
+func (c *curatorFramework) Async() AsyncCuratorFramework {
+	return &asyncCuratorFramework{c}
+}
+
+func (c *curatorFramework) Watches() WatchesBuilder {
+	c.state.Check(STARTED, "instance must be started before calling this method")
+
+	return &watchesBuilder{client: c}
+}
+
 func (c *curatorFramework) ConnectionStateListenable() ConnectionStateListenable {
 	return c.stateManager.Listenable()
 }
@@ -344,9 +517,25 @@ func (c *curatorFramework) processEvent(event CuratorEvent) {
 
 		defer tracer.Commit()
 
-		if err := l.(CuratorListener).EventReceived(c, event); err != nil {
-			c.logError(fmt.Errorf("Event listener threw exception, %s", err))
-		}
+		c.callListener(l.(CuratorListener), event)
+	})
+}
+
+// Call the listener and report its error or panic
+func (c *curatorFramework) callListener(listener CuratorListener, event CuratorEvent) {
+	info := CallbackError{Source: "CuratorListener", Operation: "EventReceived", Path: event.Path(), EventType: event.Type().String()}
+
+	c.errorHandler.callListener(listener, info, func(listener interface{}) error {
+		return listener.(CuratorListener).EventReceived(c, event)
+	})
+}
+
+// Call the background callback and report its error or panic
+func (c *curatorFramework) callBackground(callback BackgroundCallback, event CuratorEvent) {
+	info := CallbackError{Source: "BackgroundCallback", Operation: event.Type().String(), Path: event.Path(), EventType: event.Type().String()}
+
+	c.errorHandler.call(info, func() error {
+		return callback(c, event)
 	})
 }
 
@@ -371,7 +560,9 @@ func (c *curatorFramework) suspendConnection() {
 		return
 	}
 
-	go c.doSyncForSuspendedConnection(c.client.InstanceIndex())
+	instanceIndex := c.client.InstanceIndex()
+
+	c.runInBackground(func() { c.doSyncForSuspendedConnection(instanceIndex) })
 }
 
 func (c *curatorFramework) doSyncForSuspendedConnection(instanceIndex int64) {
@@ -394,15 +585,36 @@ func (c *curatorFramework) doSyncForSuspendedConnection(instanceIndex int64) {
 	if instanceIndex < 0 || instanceIndex == c.client.InstanceIndex() {
 		c.stateManager.AddStateChange(LOST)
 	} else {
-		go c.doSyncForSuspendedConnection(-1)
+		c.doSyncForSuspendedConnection(-1) // already in the worker pool, retry without queueing another task
 	}
 }
 
+// Run the background operation in the worker pool, block if the queue is full
+func (c *curatorFramework) runInBackground(task func()) error {
+	return c.backgroundPool.Execute(task)
+}
+
+// Run the background operation like runInBackground,
+// the callback receives ErrClosing if the operation is abandoned when the framework is closed.
+func (c *curatorFramework) runInBackgroundWithCallback(task func(), backgrounding backgrounding, eventType CuratorEventType, path string) error {
+	return c.backgroundPool.ExecuteOrAbandon(task, func() {
+		if backgrounding.callback != nil {
+			c.callBackground(backgrounding.callback, &curatorEvent{
+				eventType: eventType,
+				err:       ErrClosing,
+				path:      path,
+				name:      GetNodeFromPath(path),
+				context:   backgrounding.context,
+			})
+		}
+	})
+}
+
 func (c *curatorFramework) logError(err error) {
-	log.Printf("error: %s", err)
+	c.logger.Error("Unhandled error", LOG_KEY_ERROR, err)
 
 	c.unhandledErrorListeners.ForEach(func(listener interface{}) {
-		listener.(UnhandledErrorListener).UnhandledError(err)
+		notifyUnhandledError(c.logger, listener, err)
 	})
 }
 
@@ -420,14 +632,51 @@ func (c *curatorFramework) Namespace() string {
 	return c.namespace.namespace
 }
 
-func (c *curatorFramework) getNamespaceWatcher(watcher Watcher) Watcher {
-	return watcher
+// Wrap the watcher to receive the paths without the namespace and report its panics
+func (c *curatorFramework) wrapWatcher(watcher Watcher) Watcher {
+	if watcher == nil {
+		return nil
+	}
+
+	if len(c.namespace.namespace) > 0 {
+		watcher = &namespaceWatcher{watcher: watcher, unfixForNamespace: c.unfixForNamespace}
+	}
+
+	return &guardedWatcher{watcher: watcher, errors: c.errorHandler}
 }
 
 func (c *curatorFramework) ZookeeperClient() CuratorZookeeperClient {
 	return c.client
 }
 
+func (c *curatorFramework) Serializer() Serializer {
+	return c.serializer
+}
+
+func (c *curatorFramework) SchemaSet() *SchemaSet {
+	return c.schemaSet
+}
+
+func (c *curatorFramework) ConnectionStateErrorPolicy() ConnectionStateErrorPolicy {
+	return c.stateErrorPolicy
+}
+
+func (c *curatorFramework) BackgroundQueueLen() int {
+	return c.backgroundPool.Len()
+}
+
+func (c *curatorFramework) Logger() Logger {
+	return c.logger
+}
+
+func (c *curatorFramework) ActiveWatchers() int {
+	return c.client.state.watches.Len()
+}
+
+func (c *curatorFramework) RecipeMetrics() RecipeMetrics {
+	return c.recipeMetrics
+}
+
 func (c *curatorFramework) NewNamespaceAwareEnsurePath(path string) EnsurePath {
 	return NewEnsurePathWithAcl(c.fixForNamespace(path, false), c.aclProvider)
 }
@@ -439,3 +688,7 @@ func (c *curatorFramework) BlockUntilConnected() error {
 func (c *curatorFramework) BlockUntilConnectedTimeout(maxWaitTime time.Duration) error {
 	return c.stateManager.BlockUntilConnected(maxWaitTime)
 }
+
+func (c *curatorFramework) BlockUntilConnectedWithContext(ctx context.Context) error {
+	return c.stateManager.BlockUntilConnectedWithContext(ctx)
+}
@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"fmt"
+	"runtime/debug"
 	"sync"
 )
 
@@ -20,6 +22,130 @@ type UnhandledErrorListener interface {
 	UnhandledError(err error)
 }
 
+// The way to handle the errors and panics of the callbacks, listeners and watchers
+type CallbackErrorMode int32
+
+const (
+	LOG_AND_CONTINUE CallbackErrorMode = iota // report to the unhandled error listeners and continue
+	FAIL_FAST                                 // report to the unhandled error listeners and panic
+)
+
+// The error returned or the panic raised by a callback, listener or watcher,
+// which is reported to the UnhandledErrorListenable.
+type CallbackError struct {
+	Source    string      // the kind of the callback: BackgroundCallback, CuratorListener, ConnectionStateListener or Watcher
+	Operation string      // the operation which triggered the callback, e.g. CREATE or StateChanged
+	Path      string      // the path of the event, if any
+	EventType string      // the type of the event delivered to the callback
+	Err       error       // the error returned by the callback
+	Recovered interface{} // the value recovered from the panic
+	Stack     []byte      // the stack trace where the error was caught
+}
+
+func (e *CallbackError) Error() string {
+	cause := fmt.Sprintf("panic: %v", e.Recovered)
+
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("%s threw exception in %s on %s event of %s, %s", e.Source, e.Operation, e.EventType, e.Path, cause)
+	}
+
+	return fmt.Sprintf("%s threw exception in %s on %s event, %s", e.Source, e.Operation, e.EventType, cause)
+}
+
+// Call the callbacks and report their errors and panics to the unhandled error listeners
+type callbackErrorHandler struct {
+	listeners UnhandledErrorListenable
+	mode      CallbackErrorMode
+	logger    Logger
+}
+
+// Call the function, report the error it returns or the panic it raises
+func (h *callbackErrorHandler) call(info CallbackError, fn func() error) {
+	if h == nil {
+		fn()
+
+		return
+	}
+
+	if err := guardCallback(&info, fn); err != nil {
+		h.report(err)
+	}
+}
+
+// Return the handler of the callback errors of the client, or nil if the client is not created by the builder
+func errorHandlerOf(client CuratorFramework) *callbackErrorHandler {
+	switch c := client.(type) {
+	case *curatorFramework:
+		return c.errorHandler
+	case *namespaceFacade:
+		return c.errorHandler
+	}
+
+	return nil
+}
+
+// Call the listener through its executor if it was added with one, and report the error or panic of the call
+func (h *callbackErrorHandler) callListener(listener interface{}, info CallbackError, fn func(listener interface{}) error) {
+	if l, ok := listener.(*executorListener); ok {
+		l.executor.Execute(func() {
+			h.call(info, func() error { return fn(l.listener) })
+		})
+	} else {
+		h.call(info, func() error { return fn(listener) })
+	}
+}
+
+// Call the function, return the error it returns or the panic it raises with the info of the callback
+func guardCallback(info *CallbackError, fn func() error) (err *CallbackError) {
+	defer func() {
+		if r := recover(); r != nil {
+			info.Recovered = r
+			info.Stack = debug.Stack()
+
+			err = info
+		}
+	}()
+
+	if e := fn(); e != nil {
+		info.Err = e
+		info.Stack = debug.Stack()
+
+		return info
+	}
+
+	return nil
+}
+
+func (h *callbackErrorHandler) report(err *CallbackError) {
+	h.logger.Error("Callback threw exception", "source", err.Source, LOG_KEY_OP, err.Operation, LOG_KEY_PATH, err.Path, LOG_KEY_ERROR, err)
+
+	h.listeners.ForEach(func(listener interface{}) {
+		notifyUnhandledError(h.logger, listener, err)
+	})
+
+	if h.mode == FAIL_FAST {
+		panic(err)
+	}
+}
+
+// Deliver the error to the unhandled error listener,
+// the panic of the listener is logged rather than reported to the listeners again.
+func notifyUnhandledError(logger Logger, listener interface{}, err error) {
+	info := CallbackError{Source: "UnhandledErrorListener", Operation: "UnhandledError", EventType: "ERROR"}
+
+	if e := guardCallback(&info, func() error {
+		listener.(UnhandledErrorListener).UnhandledError(err)
+
+		return nil
+	}); e != nil {
+		logger.Error("Callback threw exception", "source", e.Source, LOG_KEY_OP, e.Operation, LOG_KEY_ERROR, e)
+	}
+}
+
 type connectionStateListenerCallback func(client CuratorFramework, newState ConnectionState)
 
 type connectionStateListenerStub struct {
@@ -76,6 +202,9 @@ type ConnectionStateListenable interface {
 
 	AddListener(listener ConnectionStateListener)
 
+	// Add the listener which will be called through the given executor
+	AddListenerWithExecutor(listener ConnectionStateListener, executor Executor)
+
 	RemoveListener(listener ConnectionStateListener)
 }
 
@@ -84,6 +213,9 @@ type CuratorListenable interface {
 
 	AddListener(listener CuratorListener)
 
+	// Add the listener which will be called through the given executor
+	AddListenerWithExecutor(listener CuratorListener, executor Executor)
+
 	RemoveListener(listener CuratorListener)
 }
 
@@ -92,6 +224,9 @@ type UnhandledErrorListenable interface {
 
 	AddListener(listener UnhandledErrorListener)
 
+	// Add the listener which will be called through the given executor
+	AddListenerWithExecutor(listener UnhandledErrorListener, executor Executor)
+
 	RemoveListener(listener UnhandledErrorListener)
 }
 
@@ -118,7 +253,7 @@ func (c *ListenerContainer) Remove(listener interface{}) {
 	c.lock.Lock()
 
 	for i, l := range c.listeners {
-		if l == listener {
+		if unwrapListener(l) == listener {
 			c.listeners = append(c.listeners[:i], c.listeners[i+1:]...)
 			break
 		}
@@ -169,6 +304,10 @@ func (c *connectionStateListenerContainer) AddListener(listener ConnectionStateL
 	c.Add(listener)
 }
 
+func (c *connectionStateListenerContainer) AddListenerWithExecutor(listener ConnectionStateListener, executor Executor) {
+	c.Add(&executorListener{listener: listener, executor: executor})
+}
+
 func (c *connectionStateListenerContainer) RemoveListener(listener ConnectionStateListener) {
 	c.Remove(listener)
 }
@@ -181,18 +320,28 @@ func (c *curatorListenerContainer) AddListener(listener CuratorListener) {
 	c.Add(listener)
 }
 
+func (c *curatorListenerContainer) AddListenerWithExecutor(listener CuratorListener, executor Executor) {
+	c.Add(&executorListener{listener: listener, executor: executor})
+}
+
 func (c *curatorListenerContainer) RemoveListener(listener CuratorListener) {
 	c.Remove(listener)
 }
 
 type unhandledErrorListenerContainer struct {
 	ListenerContainer
+
+	logger Logger // log the panics of the listeners added with an executor
 }
 
 func (c *unhandledErrorListenerContainer) AddListener(listener UnhandledErrorListener) {
 	c.Add(listener)
 }
 
+func (c *unhandledErrorListenerContainer) AddListenerWithExecutor(listener UnhandledErrorListener, executor Executor) {
+	c.Add(&executorListener{listener: listener, executor: executor, logger: c.logger})
+}
+
 func (c *unhandledErrorListenerContainer) RemoveListener(listener UnhandledErrorListener) {
 	c.Remove(listener)
 }
@@ -0,0 +1,234 @@
+package curator
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCallbackErrorHandler(t *testing.T) {
+	var errs []error
+
+	listeners := &unhandledErrorListenerContainer{}
+
+	listeners.AddListener(NewUnhandledErrorListener(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	h := &callbackErrorHandler{listeners: listeners, logger: NewNopLogger()}
+
+	h.call(CallbackError{Source: "CuratorListener", Operation: "EventReceived", Path: "/node", EventType: "CREATE"}, func() error {
+		return errors.New("test")
+	})
+
+	h.call(CallbackError{Source: "ConnectionStateListener", Operation: "StateChanged", EventType: "LOST"}, func() error {
+		panic("boom")
+	})
+
+	h.call(CallbackError{Source: "Watcher"}, func() error { return nil })
+
+	if assert.Equal(t, 2, len(errs)) {
+		err := errs[0].(*CallbackError)
+
+		assert.EqualError(t, err, "CuratorListener threw exception in EventReceived on CREATE event of /node, test")
+		assert.EqualError(t, err.Err, "test")
+		assert.NotEmpty(t, err.Stack)
+
+		err = errs[1].(*CallbackError)
+
+		assert.EqualError(t, err, "ConnectionStateListener threw exception in StateChanged on LOST event, panic: boom")
+		assert.Equal(t, "boom", err.Recovered)
+		assert.NotEmpty(t, err.Stack)
+	}
+
+	// fail fast
+	h.mode = FAIL_FAST
+
+	assert.Panics(t, func() {
+		h.call(CallbackError{Source: "Watcher"}, func() error { panic("boom") })
+	})
+
+	assert.Equal(t, 3, len(errs))
+
+	// called without handler
+	var nilHandler *callbackErrorHandler
+
+	called := false
+
+	nilHandler.call(CallbackError{}, func() error { called = true; return nil })
+
+	assert.True(t, called)
+}
+
+func TestCallbackErrorHandlerWithExecutor(t *testing.T) {
+	e := NewSerialExecutor(0, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	errs := make(chan error, 1)
+
+	listeners := &unhandledErrorListenerContainer{}
+
+	listeners.AddListener(NewUnhandledErrorListener(func(err error) {
+		errs <- err
+	}))
+
+	h := &callbackErrorHandler{listeners: listeners, logger: NewNopLogger()}
+
+	stateListeners := &connectionStateListenerContainer{}
+
+	stateListeners.AddListenerWithExecutor(NewConnectionStateListener(func(client CuratorFramework, newState ConnectionState) {
+		panic("boom")
+	}), e)
+
+	stateListeners.ForEach(func(listener interface{}) {
+		h.callListener(listener, CallbackError{Source: "ConnectionStateListener", Operation: "StateChanged", EventType: "LOST"}, func(listener interface{}) error {
+			listener.(ConnectionStateListener).StateChanged(nil, LOST)
+
+			return nil
+		})
+	})
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "ConnectionStateListener threw exception in StateChanged on LOST event, panic: boom")
+	case <-time.After(time.Second):
+		assert.Fail(t, "panic not reported")
+	}
+}
+
+func TestCuratorListenerWithExecutor(t *testing.T) {
+	e := NewSerialExecutor(0, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	errs := make(chan error, 1)
+
+	listeners := &unhandledErrorListenerContainer{}
+
+	listeners.AddListener(NewUnhandledErrorListener(func(err error) {
+		errs <- err
+	}))
+
+	client := &curatorFramework{errorHandler: &callbackErrorHandler{listeners: listeners, logger: NewNopLogger()}}
+
+	curatorListeners := &curatorListenerContainer{}
+
+	curatorListeners.AddListenerWithExecutor(NewCuratorListener(func(client CuratorFramework, event CuratorEvent) error {
+		return errors.New("failed")
+	}), e)
+
+	curatorListeners.ForEach(func(listener interface{}) {
+		listener.(CuratorListener).EventReceived(client, &curatorEvent{eventType: CREATE, path: "/node"})
+	})
+
+	select {
+	case err := <-errs:
+		if assert.IsType(t, &CallbackError{}, err) {
+			assert.Equal(t, "CuratorListener", err.(*CallbackError).Source)
+		}
+
+		assert.EqualError(t, err, "CuratorListener threw exception in EventReceived on CREATE event of /node, failed")
+	case <-time.After(time.Second):
+		assert.Fail(t, "error not reported")
+	}
+}
+
+func TestUnhandledErrorListenerWithExecutor(t *testing.T) {
+	e := NewSerialExecutor(0, OVERFLOW_BLOCK, nil)
+
+	defer e.Close()
+
+	errs := make(chan error, 1)
+
+	listeners := &unhandledErrorListenerContainer{logger: NewNopLogger()}
+
+	listeners.AddListenerWithExecutor(NewUnhandledErrorListener(func(err error) {
+		panic("boom")
+	}), e)
+	listeners.AddListenerWithExecutor(NewUnhandledErrorListener(func(err error) {
+		errs <- err
+	}), e)
+
+	h := &callbackErrorHandler{listeners: listeners, logger: NewNopLogger()}
+
+	for i := 0; i < 2; i++ {
+		h.call(CallbackError{Source: "Watcher", Operation: "process", EventType: "EventNodeCreated"}, func() error {
+			return errors.New("failed")
+		})
+
+		select {
+		case err := <-errs:
+			assert.EqualError(t, err, "Watcher threw exception in process on EventNodeCreated event, failed")
+		case <-time.After(time.Second):
+			assert.Fail(t, "executor stopped by the panic")
+		}
+	}
+}
+
+type CallbackErrorTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestCallbackError(t *testing.T) {
+	suite.Run(t, new(CallbackErrorTestSuite))
+}
+
+func (s *CallbackErrorTestSuite) TestBackgroundCallback() {
+	s.With(func(client CuratorFramework, conn *mockConn, wg *sync.WaitGroup) {
+		conn.On("Delete", "/node", AnyVersion).Return(nil).Once()
+
+		client.UnhandledErrorListenable().AddListener(NewUnhandledErrorListener(func(err error) {
+			defer wg.Done()
+
+			if assert.IsType(s.T(), (*CallbackError)(nil), err) {
+				e := err.(*CallbackError)
+
+				assert.Equal(s.T(), "BackgroundCallback", e.Source)
+				assert.Equal(s.T(), "DELETE", e.Operation)
+				assert.Equal(s.T(), "/node", e.Path)
+				assert.EqualError(s.T(), e.Err, "test")
+			}
+		}))
+
+		assert.NoError(s.T(), client.Delete().InBackgroundWithCallback(func(client CuratorFramework, event CuratorEvent) error {
+			return errors.New("test")
+		}).ForPath("/node"))
+	})
+}
+
+func (s *CallbackErrorTestSuite) TestWatcherPanic() {
+	s.With(func(client CuratorFramework, conn *mockConn, wg *sync.WaitGroup, data []byte, stat *zk.Stat) {
+		events := make(chan zk.Event)
+
+		defer close(events)
+
+		conn.On("GetW", "/node").Return(data, stat, events, nil).Once()
+
+		client.UnhandledErrorListenable().AddListener(NewUnhandledErrorListener(func(err error) {
+			defer wg.Done()
+
+			if assert.IsType(s.T(), (*CallbackError)(nil), err) {
+				e := err.(*CallbackError)
+
+				assert.Equal(s.T(), "Watcher", e.Source)
+				assert.Equal(s.T(), "/node", e.Path)
+				assert.Equal(s.T(), zk.EventNodeDataChanged.String(), e.EventType)
+				assert.Equal(s.T(), "boom", e.Recovered)
+			}
+		}))
+
+		_, err := client.GetData().UsingWatcher(NewWatcher(func(event *zk.Event) {
+			panic("boom")
+		})).ForPath("/node")
+
+		assert.NoError(s.T(), err)
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, Path: "/node"}
+	})
+}
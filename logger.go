@@ -0,0 +1,118 @@
+package curator
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// The severity of a log message
+type LogLevel int32
+
+const (
+	LOG_DEBUG LogLevel = iota
+	LOG_INFO
+	LOG_WARN
+	LOG_ERROR
+)
+
+var logLevelNames = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func (l LogLevel) String() string {
+	if int(l) < len(logLevelNames) {
+		return logLevelNames[l]
+	}
+
+	return fmt.Sprintf("LogLevel(%d)", l)
+}
+
+// The well-known keys of the log fields
+const (
+	LOG_KEY_SESSION_ID     = "sessionId"
+	LOG_KEY_CONNECT_STRING = "connectString"
+	LOG_KEY_NAMESPACE      = "namespace"
+	LOG_KEY_PATH           = "path"
+	LOG_KEY_OP             = "op"
+	LOG_KEY_ERROR          = "err"
+)
+
+// Structured logger with the levels and the alternating key/value fields
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+
+	Info(msg string, keyvals ...interface{})
+
+	Warn(msg string, keyvals ...interface{})
+
+	Error(msg string, keyvals ...interface{})
+
+	// Return a logger which adds the key/value fields to every message
+	With(keyvals ...interface{}) Logger
+}
+
+// The logger used when no logger has been configured
+var DefaultLogger Logger = NewStdLogger(nil, LOG_INFO)
+
+type stdLogger struct {
+	logger  *log.Logger
+	level   LogLevel
+	keyvals []interface{}
+}
+
+// Create a logger writing the messages at or above the level with the given logger, or the standard logger if nil
+func NewStdLogger(logger *log.Logger, level LogLevel) Logger {
+	return &stdLogger{logger: logger, level: level}
+}
+
+func (l *stdLogger) Debug(msg string, keyvals ...interface{}) { l.log(LOG_DEBUG, msg, keyvals) }
+func (l *stdLogger) Info(msg string, keyvals ...interface{})  { l.log(LOG_INFO, msg, keyvals) }
+func (l *stdLogger) Warn(msg string, keyvals ...interface{})  { l.log(LOG_WARN, msg, keyvals) }
+func (l *stdLogger) Error(msg string, keyvals ...interface{}) { l.log(LOG_ERROR, msg, keyvals) }
+
+func (l *stdLogger) With(keyvals ...interface{}) Logger {
+	return &stdLogger{
+		logger:  l.logger,
+		level:   l.level,
+		keyvals: append(append([]interface{}(nil), l.keyvals...), keyvals...),
+	}
+}
+
+func (l *stdLogger) log(level LogLevel, msg string, keyvals []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s: %s", level, msg)
+
+	writeKeyValues(&buf, l.keyvals)
+	writeKeyValues(&buf, keyvals)
+
+	if l.logger != nil {
+		l.logger.Print(buf.String())
+	} else {
+		log.Print(buf.String())
+	}
+}
+
+func writeKeyValues(buf *bytes.Buffer, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(buf, " %v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprintf(buf, " %v=<missing>", keyvals[i])
+		}
+	}
+}
+
+type nopLogger struct{}
+
+// Create a logger discarding all the messages
+func NewNopLogger() Logger { return nopLogger{} }
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Warn(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+func (l nopLogger) With(keyvals ...interface{}) Logger     { return l }
@@ -0,0 +1,29 @@
+//go:build go1.21
+
+package curator
+
+import (
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// Create a logger writing the messages to the slog logger, or the default slog logger if nil
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &slogLogger{logger}
+}
+
+func (l *slogLogger) Debug(msg string, keyvals ...interface{}) { l.logger.Debug(msg, keyvals...) }
+func (l *slogLogger) Info(msg string, keyvals ...interface{})  { l.logger.Info(msg, keyvals...) }
+func (l *slogLogger) Warn(msg string, keyvals ...interface{})  { l.logger.Warn(msg, keyvals...) }
+func (l *slogLogger) Error(msg string, keyvals ...interface{}) { l.logger.Error(msg, keyvals...) }
+
+func (l *slogLogger) With(keyvals ...interface{}) Logger {
+	return &slogLogger{l.logger.With(keyvals...)}
+}
@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package curator
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})
+
+	logger := NewSlogLogger(slog.New(handler)).With(LOG_KEY_NAMESPACE, "parent")
+
+	logger.Debug("debug message")
+	logger.Warn("warn message", LOG_KEY_PATH, "/node")
+
+	assert.Equal(t, "level=WARN msg=\"warn message\" namespace=parent path=/node\n", buf.String())
+}
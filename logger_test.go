@@ -0,0 +1,40 @@
+package curator
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewStdLogger(log.New(&buf, "", 0), LOG_INFO)
+
+	logger.Debug("debug message")
+	logger.Info("info message", LOG_KEY_PATH, "/node")
+
+	assert.Equal(t, "INFO: info message path=/node\n", buf.String())
+
+	buf.Reset()
+
+	logger.With(LOG_KEY_SESSION_ID, "0x1").Warn("warn message", LOG_KEY_OP, "CREATE", "dangling")
+
+	assert.Equal(t, "WARN: warn message sessionId=0x1 op=CREATE dangling=<missing>\n", buf.String())
+
+	buf.Reset()
+
+	logger.Error("error message")
+
+	assert.Equal(t, "ERROR: error message\n", buf.String())
+}
+
+func TestNopLogger(t *testing.T) {
+	logger := NewNopLogger()
+
+	logger.Error("error message")
+
+	assert.Equal(t, logger, logger.With(LOG_KEY_PATH, "/node"))
+}
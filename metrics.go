@@ -0,0 +1,65 @@
+package curator
+
+import (
+	"time"
+)
+
+// Recorder of the metrics reported by the recipes
+type RecipeMetrics interface {
+	// Record the time spent waiting for the lock on the path, and whether it was acquired
+	ObserveLockWait(path string, d time.Duration, acquired bool)
+
+	// Add to the number of the locks on the path held by this process
+	AddLocksHeld(path string, delta int)
+
+	// Set the number of the nodes held by the cache of the path
+	SetCacheSize(path string, size int)
+}
+
+// Collector of the metrics of the framework and its recipes, such as a Prometheus collector
+type MetricsCollector interface {
+	AdvancedTracerDriver
+
+	RecipeMetrics
+
+	// Called when the framework has been built, to sample its gauges such as BackgroundQueueLen and ActiveWatchers
+	Bind(client CuratorFramework)
+}
+
+type nopRecipeMetrics struct{}
+
+func (nopRecipeMetrics) ObserveLockWait(path string, d time.Duration, acquired bool) {}
+func (nopRecipeMetrics) AddLocksHeld(path string, delta int)                         {}
+func (nopRecipeMetrics) SetCacheSize(path string, size int)                          {}
+
+// The tracer driver which sends the traces to all the drivers,
+// the traces of the operations and events only to the AdvancedTracerDriver.
+type multiTracerDriver []TracerDriver
+
+func (d multiTracerDriver) AddTime(name string, t time.Duration) {
+	for _, driver := range d {
+		driver.AddTime(name, t)
+	}
+}
+
+func (d multiTracerDriver) AddCount(name string, increment int) {
+	for _, driver := range d {
+		driver.AddCount(name, increment)
+	}
+}
+
+func (d multiTracerDriver) AddTrace(trace *OperationTrace) {
+	for _, driver := range d {
+		if advanced, ok := driver.(AdvancedTracerDriver); ok {
+			advanced.AddTrace(trace)
+		}
+	}
+}
+
+func (d multiTracerDriver) AddEventTrace(trace *EventTrace) {
+	for _, driver := range d {
+		if advanced, ok := driver.(AdvancedTracerDriver); ok {
+			advanced.AddEventTrace(trace)
+		}
+	}
+}
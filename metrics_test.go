@@ -0,0 +1,80 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetricsCollector struct {
+	recordingTracerDriver
+
+	client    CuratorFramework
+	lockWaits []time.Duration
+	locksHeld int
+	cacheSize map[string]int
+}
+
+func (c *recordingMetricsCollector) Bind(client CuratorFramework) { c.client = client }
+
+func (c *recordingMetricsCollector) ObserveLockWait(path string, d time.Duration, acquired bool) {
+	c.lockWaits = append(c.lockWaits, d)
+}
+
+func (c *recordingMetricsCollector) AddLocksHeld(path string, delta int) { c.locksHeld += delta }
+
+func (c *recordingMetricsCollector) SetCacheSize(path string, size int) { c.cacheSize[path] = size }
+
+func TestMultiTracerDriver(t *testing.T) {
+	d := &mockTracerDriver{}
+	r := &recordingTracerDriver{}
+
+	multi := multiTracerDriver{d, r}
+
+	d.On("AddCount", "session-expired", 1).Return().Once()
+	r.On("AddCount", "session-expired", 1).Return().Once()
+	d.On("AddTime", "test", time.Second).Return().Once()
+	r.On("AddTime", "test", time.Second).Return().Once()
+
+	multi.AddCount("session-expired", 1)
+	multi.AddTime("test", time.Second)
+
+	// only the advanced driver receives the traces
+	multi.AddTrace(&OperationTrace{Name: "test"})
+	multi.AddEventTrace(&EventTrace{Name: "watched-event"})
+
+	assert.Equal(t, 1, len(r.traces))
+	assert.Equal(t, 1, len(r.events))
+
+	d.AssertExpectations(t)
+	r.AssertExpectations(t)
+}
+
+func TestMetricsCollector(t *testing.T) {
+	c := &recordingMetricsCollector{cacheSize: make(map[string]int)}
+
+	newMockContainer().Prepare(func(builder *CuratorFrameworkBuilder) {
+		builder.MetricsCollector = c
+	}).Test(t, func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+
+		assert.NotNil(t, c.client)
+		assert.Equal(t, c, client.RecipeMetrics())
+		assert.Equal(t, 0, client.ActiveWatchers())
+
+		_, err := client.GetData().ForPath("/node")
+
+		assert.NoError(t, err)
+
+		if assert.Equal(t, 1, len(c.traces)) {
+			assert.Equal(t, "getData", c.traces[0].Operation())
+		}
+	})
+
+	// without collector
+	newMockContainer().Test(t, func(client CuratorFramework) {
+		assert.Equal(t, nopRecipeMetrics{}, client.RecipeMetrics())
+	})
+}
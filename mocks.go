@@ -1,6 +1,7 @@
 package curator
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"reflect"
@@ -52,6 +53,28 @@ func (t *mockTracerDriver) AddCount(name string, increment int) {
 	t.Called(name, increment)
 }
 
+type mockFailedDeleteListener struct {
+	mock.Mock
+
+	log infof
+}
+
+func (l *mockFailedDeleteListener) PathAddedForDelete(path string, err error) {
+	if l.log != nil {
+		l.log("FailedDeleteListener.PathAddedForDelete(path=\"%s\", err=%v)", path, err)
+	}
+
+	l.Called(path, err)
+}
+
+func (l *mockFailedDeleteListener) PathRetriedForDelete(path string, err error) {
+	if l.log != nil {
+		l.log("FailedDeleteListener.PathRetriedForDelete(path=\"%s\", err=%v)", path, err)
+	}
+
+	l.Called(path, err)
+}
+
 type mockRetrySleeper struct {
 	mock.Mock
 
@@ -321,6 +344,71 @@ func (c *mockConn) Sync(path string) (string, error) {
 	return path, err
 }
 
+type mockExtendedConn struct {
+	mockConn
+}
+
+func (c *mockExtendedConn) Create2(path string, data []byte, flags int32, acls []zk.ACL) (string, *zk.Stat, error) {
+	args := c.Called(path, data, flags, acls)
+
+	createPath := args.String(0)
+	stat, _ := args.Get(1).(*zk.Stat)
+	err := args.Error(2)
+
+	if c.log != nil {
+		c.log("ZookeeperConnection.Create2(path=\"%s\", data=[]byte(\"%s\"), flags=%d, alcs=%v) (createdPath=\"%s\", stat=%v, error=%v)", path, data, flags, acls, createPath, stat, err)
+	}
+
+	return createPath, stat, err
+}
+
+func (c *mockExtendedConn) CreateTTL(path string, data []byte, flags int32, acls []zk.ACL, ttl time.Duration) (string, *zk.Stat, error) {
+	args := c.Called(path, data, flags, acls, ttl)
+
+	createPath := args.String(0)
+	stat, _ := args.Get(1).(*zk.Stat)
+	err := args.Error(2)
+
+	if c.log != nil {
+		c.log("ZookeeperConnection.CreateTTL(path=\"%s\", data=[]byte(\"%s\"), flags=%d, alcs=%v, ttl=%v) (createdPath=\"%s\", stat=%v, error=%v)", path, data, flags, acls, ttl, createPath, stat, err)
+	}
+
+	return createPath, stat, err
+}
+
+func (c *mockExtendedConn) AddWatch(path string, mode int32) (<-chan zk.Event, error) {
+	args := c.Called(path, mode)
+
+	events, _ := args.Get(0).(chan zk.Event)
+	err := args.Error(1)
+
+	if c.log != nil {
+		c.log("ZookeeperConnection.AddWatch(path=\"%s\", mode=%d) (events=%v, error=%v)", path, mode, events, err)
+	}
+
+	return events, err
+}
+
+func (c *mockExtendedConn) CheckWatches(path string, watcherType int32) error {
+	err := c.Called(path, watcherType).Error(0)
+
+	if c.log != nil {
+		c.log("ZookeeperConnection.CheckWatches(path=\"%s\", watcherType=%d) error=%v", path, watcherType, err)
+	}
+
+	return err
+}
+
+func (c *mockExtendedConn) RemoveWatches(path string, watcherType int32) error {
+	err := c.Called(path, watcherType).Error(0)
+
+	if c.log != nil {
+		c.log("ZookeeperConnection.RemoveWatches(path=\"%s\", watcherType=%d) error=%v", path, watcherType, err)
+	}
+
+	return err
+}
+
 type mockZookeeperDialer struct {
 	mock.Mock
 
@@ -676,6 +764,16 @@ func (c *mockCuratorFramework) InTransaction() Transaction {
 	return transaction
 }
 
+func (c *mockCuratorFramework) Update() UpdateBuilder {
+	builder, _ := c.Called().Get(0).(UpdateBuilder)
+
+	if c.log != nil {
+		c.log("CuratorFramework.Update() UpdateBuilder=%v", builder)
+	}
+
+	return builder
+}
+
 func (c *mockCuratorFramework) DoSync(path string, backgroundContextObject interface{}) {
 	c.Called(path, backgroundContextObject)
 
@@ -694,6 +792,26 @@ func (c *mockCuratorFramework) Sync() SyncBuilder {
 	return builder
 }
 
+func (c *mockCuratorFramework) Async() AsyncCuratorFramework {
+	async, _ := c.Called().Get(0).(AsyncCuratorFramework)
+
+	if c.log != nil {
+		c.log("CuratorFramework.Async() AsyncCuratorFramework=%v", async)
+	}
+
+	return async
+}
+
+func (c *mockCuratorFramework) Watches() WatchesBuilder {
+	builder, _ := c.Called().Get(0).(WatchesBuilder)
+
+	if c.log != nil {
+		c.log("CuratorFramework.Watches() WatchesBuilder=%v", builder)
+	}
+
+	return builder
+}
+
 func (c *mockCuratorFramework) ConnectionStateListenable() ConnectionStateListenable {
 	listenable, _ := c.Called().Get(0).(ConnectionStateListenable)
 
@@ -764,6 +882,76 @@ func (c *mockCuratorFramework) ZookeeperClient() CuratorZookeeperClient {
 	return client
 }
 
+func (c *mockCuratorFramework) Serializer() Serializer {
+	serializer, _ := c.Called().Get(0).(Serializer)
+
+	if c.log != nil {
+		c.log("CuratorFramework.Serializer() Serializer=%v", serializer)
+	}
+
+	return serializer
+}
+
+func (c *mockCuratorFramework) SchemaSet() *SchemaSet {
+	schemaSet, _ := c.Called().Get(0).(*SchemaSet)
+
+	if c.log != nil {
+		c.log("CuratorFramework.SchemaSet() SchemaSet=%v", schemaSet)
+	}
+
+	return schemaSet
+}
+
+func (c *mockCuratorFramework) ConnectionStateErrorPolicy() ConnectionStateErrorPolicy {
+	policy, _ := c.Called().Get(0).(ConnectionStateErrorPolicy)
+
+	if c.log != nil {
+		c.log("CuratorFramework.ConnectionStateErrorPolicy() ConnectionStateErrorPolicy=%v", policy)
+	}
+
+	return policy
+}
+
+func (c *mockCuratorFramework) BackgroundQueueLen() int {
+	n := c.Called().Int(0)
+
+	if c.log != nil {
+		c.log("CuratorFramework.BackgroundQueueLen() int=%d", n)
+	}
+
+	return n
+}
+
+func (c *mockCuratorFramework) Logger() Logger {
+	logger, _ := c.Called().Get(0).(Logger)
+
+	if c.log != nil {
+		c.log("CuratorFramework.Logger() Logger=%v", logger)
+	}
+
+	return logger
+}
+
+func (c *mockCuratorFramework) ActiveWatchers() int {
+	n := c.Called().Int(0)
+
+	if c.log != nil {
+		c.log("CuratorFramework.ActiveWatchers() int=%d", n)
+	}
+
+	return n
+}
+
+func (c *mockCuratorFramework) RecipeMetrics() RecipeMetrics {
+	metrics, _ := c.Called().Get(0).(RecipeMetrics)
+
+	if c.log != nil {
+		c.log("CuratorFramework.RecipeMetrics() RecipeMetrics=%v", metrics)
+	}
+
+	return metrics
+}
+
 func (c *mockCuratorFramework) NewNamespaceAwareEnsurePath(path string) EnsurePath {
 	ensure, _ := c.Called(path).Get(0).(EnsurePath)
 
@@ -794,6 +982,16 @@ func (c *mockCuratorFramework) BlockUntilConnectedTimeout(maxWaitTime time.Durat
 	return err
 }
 
+func (c *mockCuratorFramework) BlockUntilConnectedWithContext(ctx context.Context) error {
+	err := c.Called(ctx).Error(0)
+
+	if c.log != nil {
+		c.log("CuratorFramework.BlockUntilConnectedWithContext(ctx=%v) error=%v", ctx, err)
+	}
+
+	return err
+}
+
 type mockContainer struct {
 	builder *CuratorFrameworkBuilder
 }
@@ -0,0 +1,262 @@
+package curator
+
+import (
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// Binds a ZPath, a serializer, a create mode and ACLs to read and write the nodes as models of type T.
+//
+// The parameters of the path must be resolved with Resolved before the operations.
+type ModeledFramework[T any] struct {
+	client        CuratorFramework
+	path          *ZPath
+	resolvedPath  string
+	typed         *Typed[T]
+	createMode    CreateMode
+	acls          []zk.ACL
+	createParents bool
+	compress      bool
+}
+
+func NewModeledFramework[T any](client CuratorFramework, path *ZPath) *ModeledFramework[T] {
+	m := &ModeledFramework[T]{
+		client:        client,
+		path:          path,
+		typed:         NewTyped[T](client),
+		createParents: true,
+	}
+
+	if path.IsResolved() {
+		m.resolvedPath = path.String()
+	}
+
+	return m
+}
+
+// Return the path template of the models
+func (m *ModeledFramework[T]) ZPath() *ZPath {
+	return m.path
+}
+
+// Return the resolved path, or empty if the parameters are not resolved
+func (m *ModeledFramework[T]) Path() string {
+	return m.resolvedPath
+}
+
+// Return a copy which resolves the parameters of the path in order
+func (m *ModeledFramework[T]) Resolved(params ...string) (*ModeledFramework[T], error) {
+	path, err := m.path.Resolve(params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	modeled := *m
+
+	modeled.resolvedPath = path
+
+	return &modeled, nil
+}
+
+// Return a copy which resolves the parameters of the path by name
+func (m *ModeledFramework[T]) ResolvedMap(params map[string]string) (*ModeledFramework[T], error) {
+	path, err := m.path.ResolveMap(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	modeled := *m
+
+	modeled.resolvedPath = path
+
+	return &modeled, nil
+}
+
+// Return a copy which uses the given serializer
+func (m *ModeledFramework[T]) WithSerializer(serializer Serializer) *ModeledFramework[T] {
+	modeled := *m
+
+	modeled.typed = m.typed.WithSerializer(serializer)
+
+	return &modeled
+}
+
+// Return a copy which creates the nodes with the given mode
+func (m *ModeledFramework[T]) WithMode(mode CreateMode) *ModeledFramework[T] {
+	modeled := *m
+
+	modeled.createMode = mode
+
+	return &modeled
+}
+
+// Return a copy which creates the nodes with the given ACLs
+func (m *ModeledFramework[T]) WithACL(acls ...zk.ACL) *ModeledFramework[T] {
+	modeled := *m
+
+	modeled.acls = acls
+
+	return &modeled
+}
+
+// Return a copy which doesn't create the missing parents
+func (m *ModeledFramework[T]) WithoutParents() *ModeledFramework[T] {
+	modeled := *m
+
+	modeled.createParents = false
+
+	return &modeled
+}
+
+// Return a copy which compresses the data using the compression provider of the client
+func (m *ModeledFramework[T]) Compressed() *ModeledFramework[T] {
+	modeled := *m
+
+	modeled.typed = m.typed.Compressed()
+	modeled.compress = true
+
+	return &modeled
+}
+
+// Read the model
+func (m *ModeledFramework[T]) Read() (T, *zk.Stat, error) {
+	if path, err := m.getPath(); err != nil {
+		var zero T
+
+		return zero, nil, err
+	} else {
+		return m.typed.Get(path)
+	}
+}
+
+// Read the model, and leave a watch on the node
+func (m *ModeledFramework[T]) Watch(watcher Watcher) (T, *zk.Stat, error) {
+	if path, err := m.getPath(); err != nil {
+		var zero T
+
+		return zero, nil, err
+	} else {
+		return m.typed.Watch(path, watcher)
+	}
+}
+
+// Create the node with the model, or overwrite the data if it exists
+func (m *ModeledFramework[T]) Set(value T) (*zk.Stat, error) {
+	path, err := m.getPath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := m.typed.Serialize(path, value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stat zk.Stat
+
+	builder := m.client.Create().WithMode(m.createMode).OrSetData().StoringStatIn(&stat)
+
+	if m.createParents {
+		builder = builder.CreatingParentsIfNeeded()
+	}
+
+	if len(m.acls) > 0 {
+		builder = builder.WithACL(m.acls...)
+	}
+
+	if m.compress {
+		builder = builder.Compressed()
+	}
+
+	if _, err := builder.ForPathWithData(path, data); err != nil {
+		return nil, err
+	}
+
+	return &stat, nil
+}
+
+// Read the model, apply the update and write it back, retry if the node was changed by others.
+//
+// Each attempt updates a newly read model, and the missing node is created like Set from a new zero model.
+// Return the model written to the node.
+func (m *ModeledFramework[T]) Update(update func(value T) (T, error)) (T, *zk.Stat, error) {
+	var updated T
+
+	path, err := m.getPath()
+
+	if err != nil {
+		return updated, nil, err
+	}
+
+	builder := m.client.Update().CreatingIfMissing().WithMode(m.createMode)
+
+	if m.createParents {
+		builder = builder.CreatingParentsIfNeeded()
+	}
+
+	if len(m.acls) > 0 {
+		builder = builder.WithACL(m.acls...)
+	}
+
+	if m.compress {
+		builder = builder.Compressed()
+	}
+
+	result, err := builder.ForPath(path, func(old []byte, stat *zk.Stat) (data []byte, err error) {
+		value := newValue[T]()
+
+		if stat != nil {
+			if value, err = m.typed.Deserialize(path, old); err != nil {
+				return nil, err
+			}
+		}
+
+		if updated, err = update(value); err != nil {
+			return nil, err
+		}
+
+		return m.typed.Serialize(path, updated)
+	})
+
+	if err != nil {
+		var zero T
+
+		return zero, nil, err
+	}
+
+	return updated, result.Stat, nil
+}
+
+// Delete the node with any version
+func (m *ModeledFramework[T]) Delete() error {
+	return m.DeleteWithVersion(AnyVersion)
+}
+
+// Delete the node with the given version
+func (m *ModeledFramework[T]) DeleteWithVersion(version int32) error {
+	if path, err := m.getPath(); err != nil {
+		return err
+	} else {
+		return m.client.Delete().WithVersion(version).ForPath(path)
+	}
+}
+
+// Return the names of the child nodes
+func (m *ModeledFramework[T]) Children() ([]string, error) {
+	if path, err := m.getPath(); err != nil {
+		return nil, err
+	} else {
+		return m.client.GetChildren().ForPath(path)
+	}
+}
+
+func (m *ModeledFramework[T]) getPath() (string, error) {
+	if len(m.resolvedPath) == 0 {
+		return "", ErrUnresolvedPath
+	}
+
+	return m.resolvedPath, nil
+}
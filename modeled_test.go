@@ -0,0 +1,138 @@
+package curator
+
+import (
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModeledFrameworkTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestModeledFramework(t *testing.T) {
+	suite.Run(t, new(ModeledFrameworkTestSuite))
+}
+
+func (s *ModeledFrameworkTestSuite) TestResolve() {
+	s.With(func(client CuratorFramework) {
+		modeled := NewModeledFramework[testValue](client, MustParseZPath("/services/{name}"))
+
+		assert.Empty(s.T(), modeled.Path())
+
+		_, _, err := modeled.Read()
+
+		assert.Equal(s.T(), ErrUnresolvedPath, err)
+
+		resolved, err := modeled.Resolved("web")
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), "/services/web", resolved.Path())
+		assert.Empty(s.T(), modeled.Path())
+
+		resolved, err = modeled.ResolvedMap(map[string]string{"name": "db"})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), "/services/db", resolved.Path())
+
+		_, err = modeled.Resolved()
+
+		assert.Error(s.T(), err)
+		assert.Equal(s.T(), "/config", NewModeledFramework[testValue](client, MustParseZPath("/config")).Path())
+	})
+}
+
+func (s *ModeledFrameworkTestSuite) TestReadAndSet() {
+	s.With(func(client CuratorFramework, conn *mockConn, aclProvider *mockACLProvider, stat *zk.Stat, acls []zk.ACL) {
+		modeled, _ := NewModeledFramework[testValue](client, MustParseZPath("/services/{name}")).WithMode(EPHEMERAL).WithACL(acls...).Resolved("web")
+
+		conn.On("Create", "/services/web", []byte(`{"Name":"web","Count":80}`), int32(EPHEMERAL), acls).Return("", zk.ErrNoNode).Once()
+		conn.On("Exists", "/services").Return(false, nil, nil).Once()
+		aclProvider.On("GetAclForPath", "/services").Return(OPEN_ACL_UNSAFE).Once()
+		conn.On("Create", "/services", []byte{}, int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/services", nil).Once()
+		conn.On("Create", "/services/web", []byte(`{"Name":"web","Count":80}`), int32(EPHEMERAL), acls).Return("/services/web", nil).Once()
+		conn.On("Exists", "/services/web").Return(true, stat, nil).Once()
+
+		stat2, err := modeled.Set(testValue{Name: "web", Count: 80})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), stat, stat2)
+
+		conn.On("Get", "/services/web").Return([]byte(`{"Name":"web","Count":80}`), stat, nil).Once()
+
+		value, stat2, err := modeled.Read()
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), stat, stat2)
+		assert.Equal(s.T(), testValue{Name: "web", Count: 80}, value)
+	})
+}
+
+func (s *ModeledFrameworkTestSuite) TestUpdate() {
+	s.With(func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy) {
+		modeled, _ := NewModeledFramework[*testValue](client, MustParseZPath("/services/{name}")).Resolved("web")
+
+		conn.On("Get", "/services/web").Return([]byte(`{"Name":"web","Count":80}`), &zk.Stat{Version: 1}, nil).Once()
+		conn.On("Set", "/services/web", []byte(`{"Name":"web","Count":81}`), int32(1)).Return(nil, zk.ErrBadVersion).Once()
+		retryPolicy.On("AllowRetry", 0, mock.Anything, mock.Anything).Return(true).Once()
+		// the fields of the failed attempt should not leak into the next one
+		conn.On("Get", "/services/web").Return([]byte(`{"Count":90}`), &zk.Stat{Version: 2}, nil).Once()
+		conn.On("Set", "/services/web", []byte(`{"Name":"","Count":91}`), int32(2)).Return(&zk.Stat{Version: 3}, nil).Once()
+
+		value, stat, err := modeled.Update(func(value *testValue) (*testValue, error) {
+			value.Count++
+
+			return value, nil
+		})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), &testValue{Count: 91}, value)
+		assert.Equal(s.T(), &zk.Stat{Version: 3}, stat)
+	})
+}
+
+func (s *ModeledFrameworkTestSuite) TestUpdateMissing() {
+	s.With(func(client CuratorFramework, conn *mockConn, aclProvider *mockACLProvider, stat *zk.Stat, acls []zk.ACL) {
+		modeled, _ := NewModeledFramework[testValue](client, MustParseZPath("/services/{name}")).WithMode(EPHEMERAL).WithACL(acls...).Resolved("web")
+
+		conn.On("Get", "/services/web").Return(nil, nil, zk.ErrNoNode).Once()
+		conn.On("Create", "/services/web", []byte(`{"Name":"web","Count":0}`), int32(EPHEMERAL), acls).Return("", zk.ErrNoNode).Once()
+		conn.On("Exists", "/services").Return(false, nil, nil).Once()
+		aclProvider.On("GetAclForPath", "/services").Return(OPEN_ACL_UNSAFE).Once()
+		conn.On("Create", "/services", []byte{}, int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/services", nil).Once()
+		conn.On("Create", "/services/web", []byte(`{"Name":"web","Count":0}`), int32(EPHEMERAL), acls).Return("/services/web", nil).Once()
+		conn.On("Exists", "/services/web").Return(true, stat, nil).Once()
+
+		value, stat2, err := modeled.Update(func(value testValue) (testValue, error) {
+			assert.Equal(s.T(), testValue{}, value)
+
+			value.Name = "web"
+
+			return value, nil
+		})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), testValue{Name: "web"}, value)
+		assert.Equal(s.T(), stat, stat2)
+	})
+}
+
+func (s *ModeledFrameworkTestSuite) TestDeleteAndChildren() {
+	s.With(func(client CuratorFramework, conn *mockConn) {
+		modeled, _ := NewModeledFramework[testValue](client, MustParseZPath("/services/{name}")).Resolved("web")
+
+		conn.On("Children", "/services/web").Return([]string{"a", "b"}, nil, nil).Once()
+
+		children, err := modeled.Children()
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), []string{"a", "b"}, children)
+
+		conn.On("Delete", "/services/web", int32(3)).Return(nil).Once()
+
+		assert.NoError(s.T(), modeled.DeleteWithVersion(3))
+	})
+}
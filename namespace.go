@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/samuel/go-zookeeper/zk"
 )
 
 type namespaceImpl struct {
@@ -43,7 +45,9 @@ func FixForNamespace(namespace, path string, isSequential bool) (string, error)
 
 func (n *namespaceImpl) fixForNamespace(path string, isSequential bool) string {
 	if n.ensurePath != nil {
-		n.ensurePath.Ensure(n.client.ZookeeperClient())
+		if err := n.ensurePath.Ensure(n.client.ZookeeperClient()); err != nil {
+			n.client.logger.Warn("Fail to ensure the namespace", LOG_KEY_NAMESPACE, n.namespace, LOG_KEY_PATH, path, LOG_KEY_ERROR, err)
+		}
 	}
 
 	s, _ := FixForNamespace(n.namespace, path, isSequential)
@@ -79,6 +83,8 @@ func newNamespaceFacade(client *curatorFramework, namespace string) *namespaceFa
 	facade.namespace = newNamespace(client, namespace)
 	facade.fixForNamespace = facade.namespace.fixForNamespace
 	facade.unfixForNamespace = facade.namespace.unfixForNamespace
+	facade.listeners = &curatorListenerContainer{}
+	facade.logger = client.logger.With(LOG_KEY_NAMESPACE, namespace)
 
 	return facade
 }
@@ -91,10 +97,45 @@ func (f *namespaceFacade) Close() error {
 	return errors.New("the requested operation is not supported")
 }
 
+// Return the listenable of the facade, which only receives the events in its namespace
 func (f *namespaceFacade) CuratorListenable() CuratorListenable {
-	f.logError(errors.New("CuratorListenable() is only available from a non-namespaced CuratorFramework instance"))
+	return f.listeners
+}
+
+// Deliver the watched event to the listeners of the facade if it is in the namespace,
+// the session events without path are delivered to all the facades.
+func (f *namespaceFacade) processEvent(event *zk.Event) {
+	if len(event.Path) > 0 && len(f.namespace.namespace) > 0 {
+		prefix := JoinPath(f.namespace.namespace)
+
+		if event.Path != prefix && !strings.HasPrefix(event.Path, prefix+PATH_SEPARATOR) {
+			return
+		}
+	}
+
+	unfixed := *event
 
-	return f.curatorFramework.listeners
+	unfixed.Path = f.unfixForNamespace(event.Path)
+
+	evt := &curatorEvent{
+		eventType:    WATCHED,
+		err:          event.Err,
+		path:         unfixed.Path,
+		watchedEvent: &unfixed,
+	}
+
+	f.listeners.ForEach(func(l interface{}) {
+		f.callListener(l.(CuratorListener), evt)
+	})
+}
+
+// Call the listener with the facade and report its error or panic
+func (f *namespaceFacade) callListener(listener CuratorListener, event CuratorEvent) {
+	info := CallbackError{Source: "CuratorListener", Operation: "EventReceived", Path: event.Path(), EventType: event.Type().String()}
+
+	f.errorHandler.callListener(listener, info, func(listener interface{}) error {
+		return listener.(CuratorListener).EventReceived(f, event)
+	})
 }
 
 func (f *namespaceFacade) Namespace() string {
@@ -128,3 +169,34 @@ func (c *namespaceFacadeCache) Get(namespace string) *namespaceFacade {
 
 	return facade
 }
+
+func (c *namespaceFacadeCache) facades() []*namespaceFacade {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	facades := make([]*namespaceFacade, 0, len(c.cache))
+
+	for _, facade := range c.cache {
+		facades = append(facades, facade)
+	}
+
+	return facades
+}
+
+// Deliver the watched event to the facades
+func (c *namespaceFacadeCache) processEvent(event *zk.Event) {
+	for _, facade := range c.facades() {
+		facade.processEvent(event)
+	}
+}
+
+// Notify the listeners of the facades that the client is closing
+func (c *namespaceFacadeCache) close(event CuratorEvent) {
+	for _, facade := range c.facades() {
+		facade.listeners.ForEach(func(listener interface{}) {
+			facade.callListener(listener.(CuratorListener), event)
+		})
+
+		facade.listeners.Clear()
+	}
+}
@@ -0,0 +1,108 @@
+package curator
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceFacadeTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestNamespaceFacade(t *testing.T) {
+	suite.Run(t, new(NamespaceFacadeTestSuite))
+}
+
+func (s *NamespaceFacadeTestSuite) TestWatcher() {
+	s.With(func(client CuratorFramework, conn *mockConn, wg *sync.WaitGroup, data []byte, stat *zk.Stat) {
+		events := make(chan zk.Event)
+
+		defer close(events)
+
+		conn.On("Exists", "/parent").Return(true, nil, nil).Once()
+		conn.On("GetW", "/parent/child").Return(data, stat, events, nil).Once()
+
+		watcher := NewWatcher(func(event *zk.Event) {
+			defer wg.Done()
+
+			assert.Equal(s.T(), zk.EventNodeDataChanged, event.Type)
+			assert.Equal(s.T(), "/child", event.Path)
+		})
+
+		facade := client.UsingNamespace("parent")
+
+		_, err := facade.GetData().UsingWatcher(watcher).ForPath("/child")
+
+		assert.NoError(s.T(), err)
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/parent/child"}
+	})
+}
+
+func (s *NamespaceFacadeTestSuite) TestRemoveWatcher() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		events := make(chan zk.Event)
+
+		defer close(events)
+
+		conn.On("Exists", "/parent").Return(true, nil, nil).Once()
+		conn.On("GetW", "/parent/child").Return(data, stat, events, nil).Once()
+
+		watcher := NewWatcher(func(event *zk.Event) {
+			s.T().Error("watcher should be removed")
+		})
+
+		facade := client.UsingNamespace("parent")
+
+		_, err := facade.GetData().UsingWatcher(watcher).ForPath("/child")
+
+		assert.NoError(s.T(), err)
+		assert.NoError(s.T(), facade.Watches().Remove(watcher).Locally().ForPath("/child"))
+	})
+}
+
+func (s *NamespaceFacadeTestSuite) TestListeners() {
+	s.With(func(client CuratorFramework, events chan zk.Event, wg *sync.WaitGroup) {
+		wg.Add(1)
+
+		client.CuratorListenable().AddListener(NewCuratorListener(func(c CuratorFramework, event CuratorEvent) error {
+			if event.Type() == WATCHED && event.Path() == "/parent/node" {
+				assert.Equal(s.T(), client, c)
+
+				wg.Done()
+			}
+
+			return nil
+		}))
+
+		facade := client.UsingNamespace("parent")
+
+		facade.CuratorListenable().AddListener(NewCuratorListener(func(c CuratorFramework, event CuratorEvent) error {
+			if event.Type() == WATCHED && len(event.Path()) > 0 {
+				assert.Equal(s.T(), facade, c)
+				assert.Equal(s.T(), "/node", event.Path())
+				assert.Equal(s.T(), "/node", event.WatchedEvent().Path)
+
+				wg.Done()
+			}
+
+			return nil
+		}))
+
+		client.UsingNamespace("other").CuratorListenable().AddListener(NewCuratorListener(func(c CuratorFramework, event CuratorEvent) error {
+			if event.Type() == WATCHED && len(event.Path()) > 0 {
+				s.T().Errorf("unexpected event: %v", event)
+			}
+
+			return nil
+		}))
+
+		assert.NotEqual(s.T(), client.CuratorListenable(), facade.CuratorListenable())
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/parent/node"}
+	})
+}
@@ -138,6 +138,16 @@ func ValidatePath(path string) error {
 
 // Make sure all the nodes in the path are created
 func MakeDirs(conn ZookeeperConnection, path string, makeLastNode bool, aclProvider ACLProvider) error {
+	return makeDirs(conn, path, makeLastNode, aclProvider, false)
+}
+
+// Make sure all the nodes in the path are created as containers,
+// which will be deleted by the server once their last child is deleted.
+func MakeDirsAsContainers(conn ZookeeperConnection, path string, makeLastNode bool, aclProvider ACLProvider) error {
+	return makeDirs(conn, path, makeLastNode, aclProvider, true)
+}
+
+func makeDirs(conn ZookeeperConnection, path string, makeLastNode bool, aclProvider ACLProvider, asContainers bool) error {
 	if err := ValidatePath(path); err != nil {
 		return err
 	}
@@ -160,19 +170,15 @@ func MakeDirs(conn ZookeeperConnection, path string, makeLastNode bool, aclProvi
 		if exists, _, err := conn.Exists(subPath); err != nil {
 			return err
 		} else if !exists {
-			var acls []zk.ACL
+			acls := getAclForDir(aclProvider, subPath)
 
-			if aclProvider != nil {
-				if acls = aclProvider.GetAclForPath(subPath); len(acls) == 0 {
-					acls = aclProvider.GetDefaultAcl()
-				}
-			}
+			mode := PERSISTENT
 
-			if acls == nil {
-				acls = OPEN_ACL_UNSAFE
+			if asContainers {
+				mode = CONTAINER
 			}
 
-			if _, err := conn.Create(subPath, []byte{}, int32(PERSISTENT), acls); err != nil && err != zk.ErrNodeExists {
+			if _, _, err := createNode(conn, subPath, []byte{}, mode, acls, 0); err != nil && err != zk.ErrNodeExists {
 				return err
 			}
 		}
@@ -181,6 +187,23 @@ func MakeDirs(conn ZookeeperConnection, path string, makeLastNode bool, aclProvi
 	return nil
 }
 
+// Get the ACL list of the parent node which will be created
+func getAclForDir(aclProvider ACLProvider, path string) []zk.ACL {
+	var acls []zk.ACL
+
+	if aclProvider != nil {
+		if acls = aclProvider.GetAclForPath(path); len(acls) == 0 {
+			acls = aclProvider.GetDefaultAcl()
+		}
+	}
+
+	if acls == nil {
+		acls = OPEN_ACL_UNSAFE
+	}
+
+	return acls
+}
+
 // Recursively deletes children of a node.
 func DeleteChildren(conn ZookeeperConnection, path string, deleteSelf bool) error {
 	if err := ValidatePath(path); err != nil {
@@ -240,7 +263,7 @@ func (h *ensurePathHelper) Ensure(client CuratorZookeeperClient, path string, ma
 		_, err := client.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
 			if conn, err := client.Conn(); err != nil {
 				return nil, err
-			} else if err := MakeDirs(conn, path, makeLastNode, h.owner.aclProvider); err != nil {
+			} else if err := makeDirs(conn, path, makeLastNode, h.owner.aclProvider, h.owner.asContainers); err != nil {
 				return nil, err
 			} else {
 				return nil, nil
@@ -262,6 +285,7 @@ type ensurePath struct {
 	path         string
 	aclProvider  ACLProvider
 	makeLastNode bool
+	asContainers bool
 	helper       EnsurePathHelper
 }
 
@@ -289,11 +313,25 @@ func NewEnsurePathWithAclAndHelper(path string, aclProvider ACLProvider, helper
 	return p
 }
 
+// Ensure the path is created as containers, which will be deleted by the server once their last child is deleted
+func NewEnsureContainers(path string) *ensurePath {
+	return NewEnsureContainersWithAcl(path, nil)
+}
+
+func NewEnsureContainersWithAcl(path string, aclProvider ACLProvider) *ensurePath {
+	p := NewEnsurePathWithAclAndHelper(path, aclProvider, nil)
+
+	p.asContainers = true
+
+	return p
+}
+
 func (p *ensurePath) ExcludingLast() EnsurePath {
 	return &ensurePath{
 		path:         p.path,
 		aclProvider:  p.aclProvider,
 		makeLastNode: false,
+		asContainers: p.asContainers,
 		helper:       p.helper,
 	}
 }
@@ -120,6 +120,28 @@ func TestMakeDirs(t *testing.T) {
 	acls.AssertExpectations(t)
 }
 
+func TestMakeDirsAsContainers(t *testing.T) {
+	// fall back to the persistent nodes
+	conn := &mockConn{}
+
+	conn.On("Exists", "/parent").Return(false, nil, nil).Once()
+	conn.On("Create", "/parent", []byte{}, int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/parent", nil).Once()
+
+	assert.NoError(t, MakeDirsAsContainers(conn, "/parent/node", false, nil))
+
+	conn.AssertExpectations(t)
+
+	// create `parent` as container
+	extConn := &mockExtendedConn{}
+
+	extConn.On("Exists", "/parent").Return(false, nil, nil).Once()
+	extConn.On("Create2", "/parent", []byte{}, int32(CONTAINER), OPEN_ACL_UNSAFE).Return("/parent", nil, nil).Once()
+
+	assert.NoError(t, MakeDirsAsContainers(extConn, "/parent/node", false, nil))
+
+	extConn.AssertExpectations(t)
+}
+
 func TestDeleteChildren(t *testing.T) {
 	// Delete children
 	conn := &mockConn{}
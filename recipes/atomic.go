@@ -234,7 +234,7 @@ func (v *distributedAtomicValue) tryOptimistic(result *mutableAtomicValue, newVa
 			result.succeeded = true
 
 			break
-		} else if !v.retryPolicy.AllowRetry(result.stats.OptimisticTries, time.Now().Sub(startTime), curator.DefaultRetrySleeper) {
+		} else if !v.retryPolicy.AllowRetry(result.stats.OptimisticTries-1, time.Now().Sub(startTime), curator.DefaultRetrySleeper) {
 			break
 		}
 	}
@@ -289,7 +289,7 @@ func (v *distributedAtomicValue) tryWithMutex(result *mutableAtomicValue, newVal
 				result.succeeded = true
 
 				break
-			} else if !v.promotedToLock.retryPolicy.AllowRetry(result.stats.PromotedTries, time.Now().Sub(startTime), curator.DefaultRetrySleeper) {
+			} else if !v.promotedToLock.retryPolicy.AllowRetry(result.stats.PromotedTries-1, time.Now().Sub(startTime), curator.DefaultRetrySleeper) {
 				break
 			}
 		}
@@ -100,6 +100,8 @@ func NewNodeCache(client curator.CuratorFramework, path string, dataIsCompressed
 		listeners:        &NodeCacheListenerContainer{},
 	}
 
+	// the cache is only reset after reconnected from the error states of the ConnectionStateErrorPolicy,
+	// e.g. the watches are kept when the connection is SUSPENDED but the session is still alive
 	c.connectionStateListener = curator.NewConnectionStateListener(func(client curator.CuratorFramework, newState curator.ConnectionState) {
 		if newState.Connected() {
 			if c.isConnected.CompareAndSwap(false, true) {
@@ -107,13 +109,15 @@ func NewNodeCache(client curator.CuratorFramework, path string, dataIsCompressed
 					panic(fmt.Errorf("Trying to reset after reconnection, %s", err))
 				}
 			}
-		} else {
+		} else if c.client.ConnectionStateErrorPolicy().IsErrorState(newState) {
 			c.isConnected.Set(false)
 		}
 	})
 
 	c.watcher = curator.NewWatcher(func(event *zk.Event) {
-		c.reset()
+		if err := c.reset(); err != nil {
+			c.client.Logger().Warn("Fail to reset the node cache", curator.LOG_KEY_PATH, c.path, curator.LOG_KEY_ERROR, err)
+		}
 	})
 
 	c.backgroundCallback = func(client curator.CuratorFramework, event curator.CuratorEvent) error {
@@ -172,8 +176,12 @@ func (c *NodeCache) internalRebuild() error {
 
 	if data, err := builder.StoringStatIn(&stat).ForPath(c.path); err == nil {
 		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&c.data)), unsafe.Pointer(&ChildData{c.path, &stat, data}))
+
+		c.client.RecipeMetrics().SetCacheSize(c.path, 1)
 	} else if err == zk.ErrNoNode {
 		atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&c.data)), nil)
+
+		c.client.RecipeMetrics().SetCacheSize(c.path, 0)
 	} else {
 		return err
 	}
@@ -196,6 +204,8 @@ func (c *NodeCache) processBackgroundResult(event curator.CuratorEvent) error {
 	case curator.GET_DATA:
 		if event.Err() == nil {
 			c.setNewData(&ChildData{c.path, event.Stat(), event.Data()})
+		} else if event.Err() != zk.ErrNoNode {
+			c.client.Logger().Warn("Fail to get the data of the node cache", curator.LOG_KEY_PATH, c.path, curator.LOG_KEY_ERROR, event.Err())
 		}
 	case curator.EXISTS:
 		if event.Err() == zk.ErrNoNode {
@@ -217,6 +227,12 @@ func (c *NodeCache) processBackgroundResult(event curator.CuratorEvent) error {
 func (c *NodeCache) setNewData(newData *ChildData) {
 	previousData := (*ChildData)(atomic.SwapPointer((*unsafe.Pointer)(unsafe.Pointer(&c.data)), unsafe.Pointer(newData)))
 
+	if newData != nil {
+		c.client.RecipeMetrics().SetCacheSize(c.path, 1)
+	} else {
+		c.client.RecipeMetrics().SetCacheSize(c.path, 0)
+	}
+
 	if !reflect.DeepEqual(previousData, newData) {
 		c.listeners.ForEach(func(listener interface{}) {
 			listener.(NodeCacheListener).NodeChanged()
@@ -264,7 +280,7 @@ func NewPathChildrenCache(client curator.CuratorFramework, path string, cacheDat
 					}
 				*/
 			}
-		} else {
+		} else if c.client.ConnectionStateErrorPolicy().IsErrorState(newState) {
 			c.isConnected.Set(false)
 		}
 	})
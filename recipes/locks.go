@@ -98,9 +98,9 @@ func (d *StandardLockInternalsDriver) GetsTheLock(client curator.CuratorFramewor
 
 func (d *StandardLockInternalsDriver) CreatesTheLock(client curator.CuratorFramework, path string, lockNodeBytes []byte) (string, error) {
 	if lockNodeBytes == nil {
-		return client.Create().CreatingParentsIfNeeded().WithMode(curator.EPHEMERAL_SEQUENTIAL).ForPath(path)
+		return client.Create().CreatingParentContainersIfNeeded().WithProtection().WithMode(curator.EPHEMERAL_SEQUENTIAL).ForPath(path)
 	} else {
-		return client.Create().CreatingParentsIfNeeded().WithMode(curator.EPHEMERAL_SEQUENTIAL).ForPathWithData(path, lockNodeBytes)
+		return client.Create().CreatingParentContainersIfNeeded().WithProtection().WithMode(curator.EPHEMERAL_SEQUENTIAL).ForPathWithData(path, lockNodeBytes)
 	}
 }
 
@@ -161,6 +161,8 @@ func (m *InterProcessMutex) Release() error {
 	case count < 0:
 		return fmt.Errorf("Lock count has gone negative for lock: %s", m.basePath)
 	default:
+		m.internals.client.RecipeMetrics().AddLocksHeld(m.basePath, -1)
+
 		return m.internals.releaseLock(m.lockPath)
 	}
 }
@@ -177,13 +179,23 @@ func (m *InterProcessMutex) internalLock(expires time.Duration) (bool, error) {
 		return true, nil
 	}
 
-	if lockPath, err := m.internals.attemptLock(expires, m.LockNodeBytes); err != nil {
+	startTime := time.Now()
+
+	lockPath, err := m.internals.attemptLock(expires, m.LockNodeBytes)
+
+	metrics := m.internals.client.RecipeMetrics()
+
+	metrics.ObserveLockWait(m.basePath, time.Since(startTime), err == nil && len(lockPath) > 0)
+
+	if err != nil {
 		return false, err
 	} else if len(lockPath) > 0 {
 		m.lockPath = lockPath
 
 		atomic.StoreInt32(&m.lockCount, 1)
 
+		metrics.AddLocksHeld(m.basePath, 1)
+
 		return true, nil
 	}
 
@@ -233,9 +245,9 @@ func (l *lockInternals) attemptLock(waitTime time.Duration, lockNodeBytes []byte
 		}
 
 		if err == zk.ErrNoNode {
-			retryCount++
-
 			if l.client.ZookeeperClient().RetryPolicy().AllowRetry(retryCount, time.Now().Sub(startTime), curator.DefaultRetrySleeper) {
+				retryCount++
+
 				continue
 			}
 		}
@@ -260,8 +272,26 @@ func (l *lockInternals) deleteOurPath(path string) error {
 
 func (l *lockInternals) internalLockLoop(startTime time.Time, waitTime time.Duration, path string) (haveTheLock bool, err error) {
 	var doDelete bool
+	var stateErr error
+
+	// stop waiting once the connection enters an error state of the ConnectionStateErrorPolicy,
+	// since the lock node may be gone with the session
+	errorStates := make(chan curator.ConnectionState, 1)
 
-	for l.client.State() == curator.STARTED && !haveTheLock {
+	listener := curator.NewConnectionStateListener(func(client curator.CuratorFramework, newState curator.ConnectionState) {
+		if l.client.ConnectionStateErrorPolicy().IsErrorState(newState) {
+			select {
+			case errorStates <- newState:
+			default:
+			}
+		}
+	})
+
+	l.client.ConnectionStateListenable().AddListener(listener)
+
+	defer l.client.ConnectionStateListenable().RemoveListener(listener)
+
+	for l.client.State() == curator.STARTED && !haveTheLock && stateErr == nil {
 		if children, err := l.getSortedChildren(); err != nil {
 			break
 		} else {
@@ -276,7 +306,7 @@ func (l *lockInternals) internalLockLoop(startTime time.Time, waitTime time.Dura
 			} else {
 				previousSequencePath := curator.JoinPath(l.basePath, results.PathToWatch)
 
-				c := make(chan error)
+				c := make(chan error, 1) // the watcher may fire after we stopped waiting
 
 				t := time.NewTimer(waitTime - time.Now().Sub(startTime))
 
@@ -290,13 +320,23 @@ func (l *lockInternals) internalLockLoop(startTime time.Time, waitTime time.Dura
 						break
 					}
 				case <-t.C:
+				case state := <-errorStates:
+					stateErr = fmt.Errorf("Connection %s while trying to acquire lock: %s", state, l.basePath)
 				}
+
+				t.Stop()
 			}
 		}
 	}
 
+	if stateErr != nil {
+		err = stateErr
+	}
+
 	if err != nil || doDelete {
-		l.deleteOurPath(path)
+		if err := l.deleteOurPath(path); err != nil {
+			l.client.Logger().Warn("Fail to delete the lock node", curator.LOG_KEY_PATH, path, curator.LOG_KEY_ERROR, err)
+		}
 	}
 
 	return haveTheLock, err
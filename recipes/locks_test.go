@@ -1,9 +1,13 @@
 package recipes
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/flier/curator.go"
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/mock"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -78,6 +82,10 @@ func TestLockInternalsDriver(t *testing.T) {
 			})
 
 			Convey("When creates the lock", func() {
+				protectedPath := mock.MatchedBy(func(path string) bool {
+					return strings.HasPrefix(path, "/"+curator.PROTECTED_PREFIX) && strings.HasSuffix(path, "-lock")
+				})
+
 				mocks := newMockBuilder(t)
 
 				client := mocks.Build()
@@ -85,7 +93,7 @@ func TestLockInternalsDriver(t *testing.T) {
 				So(client.Start(), ShouldBeNil)
 
 				Convey("When lock with data", func() {
-					mocks.conn.On("Create", "/lock", []byte("data"), int32(curator.EPHEMERAL_SEQUENTIAL), curator.OPEN_ACL_UNSAFE).Return("/lock", nil).Once()
+					mocks.conn.On("Create", protectedPath, []byte("data"), int32(curator.EPHEMERAL_SEQUENTIAL), curator.OPEN_ACL_UNSAFE).Return("/lock", nil).Once()
 
 					path, err := driver.CreatesTheLock(client, "/lock", []byte("data"))
 
@@ -98,7 +106,7 @@ func TestLockInternalsDriver(t *testing.T) {
 				})
 
 				Convey("When lock without data", func() {
-					mocks.conn.On("Create", "/lock", mocks.builder.DefaultData, int32(curator.EPHEMERAL_SEQUENTIAL), curator.OPEN_ACL_UNSAFE).Return("/lock", nil).Once()
+					mocks.conn.On("Create", protectedPath, mocks.builder.DefaultData, int32(curator.EPHEMERAL_SEQUENTIAL), curator.OPEN_ACL_UNSAFE).Return("/lock", nil).Once()
 
 					path, err := driver.CreatesTheLock(client, "/lock", nil)
 
@@ -140,6 +148,40 @@ func TestLockInternals(t *testing.T) {
 	})
 }
 
+func TestLockInternalsErrorState(t *testing.T) {
+	Convey("Given lockInternals waiting for the lock", t, func() {
+		mocks := newMockBuilder(t)
+
+		client := mocks.Build()
+
+		So(client.Start(), ShouldBeNil)
+
+		internal, err := newLockInternals(client, mocks.driver, "/path", LockPrefix, 1)
+
+		So(err, ShouldBeNil)
+
+		mocks.conn.On("Children", "/path").Return([]string{"lock-1", "lock-2"}, nil, nil)
+		mocks.driver.On("FixForSorting", mock.Anything, LockPrefix).Return("")
+		mocks.driver.On("GetsTheLock", client, []string{"lock-1", "lock-2"}, "lock-2", 1).Return(&PredicateResults{PathToWatch: "lock-1"}, nil)
+		mocks.conn.On("GetW", "/path/lock-1").Return([]byte{}, &zk.Stat{}, make(chan zk.Event), nil).Run(func(args mock.Arguments) {
+			mocks.events <- zk.Event{Type: zk.EventSession, State: zk.StateDisconnected}
+		}).Once()
+		mocks.conn.On("Sync", "/").Return("/", nil).Maybe()
+		mocks.conn.On("Delete", "/path/lock-2", int32(-1)).Return(nil).Once()
+
+		Convey("When the connection is suspended", func() {
+			hasTheLock, err := internal.internalLockLoop(time.Now(), time.Minute, "/path/lock-2")
+
+			Convey("Stop waiting and delete the lock node", func() {
+				So(hasTheLock, ShouldBeFalse)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		mocks.Check(t)
+	})
+}
+
 func TestInterProcessMutex(t *testing.T) {
 	Convey("Given an InterProcessMutex base on a path", t, func() {
 
@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"context"
+	"errors"
 	"math"
 	"math/rand"
 	"net"
@@ -19,9 +21,50 @@ type RetrySleeper interface {
 type RetryPolicy interface {
 	// Called when an operation has failed for some reason.
 	// This method should return true to make another attempt.
+	//
+	// The retryCount is the number of times retried before, so it is 0 for the first retry,
+	// and the policies allowing N retries allow the retry counts from 0 to N-1.
 	AllowRetry(retryCount int, elapsedTime time.Duration, sleeper RetrySleeper) bool
 }
 
+// Decides whether an error returned by an operation is retryable
+type RetryClassifier interface {
+	// Return true if the operation failed with the error should be retried
+	IsRetryable(err error) bool
+}
+
+type retryClassifierCallback struct {
+	callback func(err error) bool
+}
+
+// Create a RetryClassifier with the callback
+func NewRetryClassifier(callback func(err error) bool) RetryClassifier {
+	return &retryClassifierCallback{callback}
+}
+
+func (c *retryClassifierCallback) IsRetryable(err error) bool { return c.callback(err) }
+
+// The classifier which retries the connection and session errors, and the network timeouts
+type StandardRetryClassifier struct{}
+
+func NewStandardRetryClassifier() *StandardRetryClassifier { return &StandardRetryClassifier{} }
+
+func (c *StandardRetryClassifier) IsRetryable(err error) bool {
+	for _, retryable := range []error{zk.ErrSessionExpired, zk.ErrSessionMoved, zk.ErrConnectionClosed, ErrConnectionLoss} {
+		if errors.Is(err, retryable) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+
+	if errors.As(err, &netErr) {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+
+	return false
+}
+
 type defaultRetrySleeper struct {
 }
 
@@ -33,10 +76,46 @@ func (s *defaultRetrySleeper) SleepFor(d time.Duration) error {
 	return nil
 }
 
+// Retry sleeper that wakes up early when the context is done
+type contextRetrySleeper struct {
+	ctx     context.Context
+	sleeper RetrySleeper
+}
+
+func (s *contextRetrySleeper) SleepFor(d time.Duration) error {
+	if s.ctx.Done() == nil {
+		return s.sleeper.SleepFor(d)
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	c := make(chan error, 1)
+
+	go func() {
+		c <- s.sleeper.SleepFor(d)
+	}()
+
+	select {
+	case err := <-c:
+		if err == nil {
+			err = s.ctx.Err()
+		}
+
+		return err
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
 // Mechanism to perform an operation on Zookeeper that is safe against disconnections and "recoverable" errors.
 type RetryLoop interface {
 	// creates a retry loop calling the given proc and retrying if needed
 	CallWithRetry(proc func() (interface{}, error)) (interface{}, error)
+
+	// creates a retry loop calling the given proc and retrying if needed, until the context is done
+	CallWithRetryContext(ctx context.Context, proc func() (interface{}, error)) (interface{}, error)
 }
 
 type retryLoop struct {
@@ -46,6 +125,9 @@ type retryLoop struct {
 	retryPolicy  RetryPolicy
 	retrySleeper RetrySleeper
 	tracer       TracerDriver
+	classifier   RetryClassifier // decide which errors are retryable, default to the StandardRetryClassifier
+	trace        *OperationTrace // the trace of the operation, committed when the loop completes
+	sessionId    func() int64    // return the current session id
 }
 
 func newRetryLoop(retryPolicy RetryPolicy, tracer TracerDriver) *retryLoop {
@@ -58,39 +140,147 @@ func newRetryLoop(retryPolicy RetryPolicy, tracer TracerDriver) *retryLoop {
 
 // return true if the given Zookeeper result code is retry-able
 func (l *retryLoop) ShouldRetry(err error) bool {
-	if err == zk.ErrSessionExpired || err == zk.ErrSessionMoved {
-		return true
+	if l.classifier != nil {
+		return l.classifier.IsRetryable(err)
 	}
 
-	if netErr, ok := err.(net.Error); ok {
-		return netErr.Timeout() || netErr.Temporary()
+	return NewStandardRetryClassifier().IsRetryable(err)
+}
+
+func (l *retryLoop) CallWithRetry(proc func() (interface{}, error)) (interface{}, error) {
+	return l.CallWithRetryContext(context.Background(), proc)
+}
+
+func (l *retryLoop) CallWithRetryContext(ctx context.Context, proc func() (interface{}, error)) (interface{}, error) {
+	ret, err := l.callWithRetry(ctx, proc)
+
+	l.commitTrace(ctx, ret, err)
+
+	return ret, err
+}
+
+// Record the trace of the operation if the tracer is an AdvancedTracerDriver
+func (l *retryLoop) commitTrace(ctx context.Context, ret interface{}, err error) {
+	driver, ok := l.tracer.(AdvancedTracerDriver)
+
+	if !ok {
+		return
 	}
 
-	return false
+	trace := l.trace
+
+	if trace == nil {
+		trace = &OperationTrace{Op: "retry-loop", Name: "retry-loop"}
+	}
+
+	trace.Context = ctx
+	trace.StartTime = l.startTime
+	trace.Latency = time.Since(l.startTime)
+	trace.ResponseBytes = responseSize(ret)
+	trace.Err = err
+	trace.RetryCount = l.retryCount
+
+	if l.sessionId != nil {
+		trace.SessionId = l.sessionId()
+	}
+
+	driver.AddTrace(trace)
 }
 
-func (l *retryLoop) CallWithRetry(proc func() (interface{}, error)) (interface{}, error) {
+func (l *retryLoop) callWithRetry(ctx context.Context, proc func() (interface{}, error)) (interface{}, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	for {
-		if ret, err := proc(); err == nil || !l.ShouldRetry(err) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if ret, err := callWithContext(ctx, proc); err == nil || !l.ShouldRetry(err) {
 			return ret, err
 		} else {
+			retryCount := l.retryCount
+
 			l.retryCount++
 
-			if sleeper := l.retrySleeper; sleeper == nil {
+			sleeper := l.retrySleeper
+
+			if sleeper == nil {
 				sleeper = DefaultRetrySleeper
-			} else {
-				if !l.retryPolicy.AllowRetry(l.retryCount, time.Now().Sub(l.startTime), sleeper) {
-					l.tracer.AddCount("retries-disallowed", 1)
+			}
+
+			if l.retryPolicy == nil || !l.retryPolicy.AllowRetry(retryCount, time.Now().Sub(l.startTime), &contextRetrySleeper{ctx, sleeper}) {
+				l.addCount("retries-disallowed")
 
-					return ret, err
-				} else {
-					l.tracer.AddCount("retries-allowed", 1)
+				if err := ctx.Err(); err != nil {
+					return nil, err
 				}
+
+				return ret, err
 			}
+
+			l.addCount("retries-allowed")
 		}
 	}
+}
 
-	return nil, nil
+func (l *retryLoop) addCount(name string) {
+	if l.tracer != nil {
+		l.tracer.AddCount(name, 1)
+	}
+}
+
+// Call the proc, but stop waiting for it when the context is done.
+//
+// ZooKeeper requests can't be cancelled on the wire,
+// so an abandoned request may still complete after ctx.Err() has been returned.
+// The proc must not write into the state of the caller, but return its outcome, e.g. as an attemptResult.
+func callWithContext(ctx context.Context, proc func() (interface{}, error)) (interface{}, error) {
+	if ctx.Done() == nil {
+		return proc()
+	}
+
+	type result struct {
+		ret interface{}
+		err error
+	}
+
+	c := make(chan result, 1)
+
+	go func() {
+		ret, err := proc()
+
+		c <- result{ret, err}
+	}()
+
+	select {
+	case res := <-c:
+		return res.ret, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// The outcome of an attempt which is applied to the builder only when the retry loop returns it,
+// so an attempt abandoned when the context is done never writes into the stat or registers the watcher of the caller.
+type attemptResult struct {
+	value  interface{}     // the value returned by the operation
+	stat   *zk.Stat        // the stat of the node, if any
+	events <-chan zk.Event // the events of the watch set by the operation, if any
+}
+
+// Copy the stat into the one given by StoringStatIn, or keep it for the background callback
+func (r *attemptResult) storeStatIn(stat **zk.Stat) {
+	if r.stat == nil {
+		return
+	}
+
+	if *stat != nil {
+		**stat = *r.stat
+	} else {
+		*stat = r.stat
+	}
 }
 
 type SleepingRetry struct {
@@ -187,3 +377,101 @@ func NewRetryUntilElapsed(maxElapsedTime, sleepBetweenRetries time.Duration) *Re
 func (r *RetryUntilElapsed) AllowRetry(retryCount int, elapsedTime time.Duration, sleeper RetrySleeper) bool {
 	return elapsedTime < r.maxElapsedTime && r.SleepingRetry.AllowRetry(retryCount, elapsedTime, sleeper)
 }
+
+// Retry policy that retries infinitely until it succeeds
+type RetryForever struct {
+	SleepingRetry
+}
+
+func NewRetryForever(sleepBetweenRetries time.Duration) *RetryForever {
+	return &RetryForever{
+		SleepingRetry: SleepingRetry{
+			N:            math.MaxInt64,
+			getSleepTime: func(retryCount int, elapsedTime time.Duration) time.Duration { return sleepBetweenRetries },
+		},
+	}
+}
+
+// Retry policy that retries a set number of times with increasing sleep time between retries, bounded by the max sleep time
+type BoundedExponentialBackoffRetry struct {
+	ExponentialBackoffRetry
+
+	maxSleepTime time.Duration
+}
+
+func NewBoundedExponentialBackoffRetry(baseSleepTime, maxSleepTime time.Duration, maxRetries int) *BoundedExponentialBackoffRetry {
+	return &BoundedExponentialBackoffRetry{
+		ExponentialBackoffRetry: *NewExponentialBackoffRetry(baseSleepTime, maxRetries, maxSleepTime),
+		maxSleepTime:            maxSleepTime,
+	}
+}
+
+// Return the max sleep time between the retries
+func (r *BoundedExponentialBackoffRetry) MaxSleepTime() time.Duration { return r.maxSleepTime }
+
+// Retry policy that retries a set number of times with the decorrelated jitter backoff,
+// which sleeps a random time between the base sleep time and an upper bound, bounded by the max sleep time.
+//
+// The upper bound starts from three times the base sleep time and triples on each retry,
+// it is derived from the retry count, so the policy can be shared by the retry loops.
+type DecorrelatedJitterRetry struct {
+	SleepingRetry
+}
+
+func NewDecorrelatedJitterRetry(baseSleepTime, maxSleepTime time.Duration, maxRetries int) *DecorrelatedJitterRetry {
+	return &DecorrelatedJitterRetry{
+		SleepingRetry: SleepingRetry{
+			N: maxRetries,
+			getSleepTime: func(retryCount int, elapsedTime time.Duration) time.Duration {
+				upper := baseSleepTime
+
+				for i := 0; i <= retryCount && upper < maxSleepTime; i++ {
+					if upper > maxSleepTime/3 {
+						upper = maxSleepTime
+					} else {
+						upper *= 3
+					}
+				}
+
+				sleepTime := baseSleepTime
+
+				if upper > baseSleepTime {
+					sleepTime += time.Duration(rand.Int63n(int64(upper - baseSleepTime)))
+				}
+
+				if sleepTime > maxSleepTime {
+					sleepTime = maxSleepTime
+				}
+
+				return sleepTime
+			},
+		},
+	}
+}
+
+// Retry policy that delegates to the policy until the connection state becomes LOST,
+// it gives up retrying until the connection has been re-established.
+//
+// The policy is registered as a ConnectionStateListener when it is used as the RetryPolicy of the builder,
+// otherwise it should be added to the ConnectionStateListenable of the client.
+type SessionFailedRetryPolicy struct {
+	RetryPolicy
+
+	lost AtomicBool
+}
+
+func NewSessionFailedRetryPolicy(delegate RetryPolicy) *SessionFailedRetryPolicy {
+	return &SessionFailedRetryPolicy{RetryPolicy: delegate}
+}
+
+func (r *SessionFailedRetryPolicy) AllowRetry(retryCount int, elapsedTime time.Duration, sleeper RetrySleeper) bool {
+	return !r.lost.Load() && r.RetryPolicy.AllowRetry(retryCount, elapsedTime, sleeper)
+}
+
+func (r *SessionFailedRetryPolicy) StateChanged(client CuratorFramework, newState ConnectionState) {
+	if newState == LOST {
+		r.lost.Set(true)
+	} else if newState.Connected() {
+		r.lost.Set(false)
+	}
+}
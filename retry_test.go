@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -45,6 +47,125 @@ func TestRetryLoop(t *testing.T) {
 	})
 
 	assert.EqualError(t, err, zk.ErrClosing.Error())
+
+	// retry policy is consulted without the sleeper
+	retryPolicy := &mockRetryPolicy{}
+
+	retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+	retryLoop = newRetryLoop(retryPolicy, nil)
+
+	_, err = retryLoop.CallWithRetry(func() (interface{}, error) {
+		return nil, zk.ErrConnectionClosed
+	})
+
+	assert.EqualError(t, err, zk.ErrConnectionClosed.Error())
+	assert.Equal(t, 1, retryLoop.retryCount)
+
+	retryPolicy.AssertExpectations(t)
+
+	// retry one time
+	retryLoop = newRetryLoop(NewRetryOneTime(0), nil)
+
+	calls := 0
+
+	_, err = retryLoop.CallWithRetry(func() (interface{}, error) {
+		calls++
+
+		return nil, zk.ErrConnectionClosed
+	})
+
+	assert.EqualError(t, err, zk.ErrConnectionClosed.Error())
+	assert.Equal(t, 2, calls)
+
+	// retry N times, the first attempt is not counted as a retry
+	retryLoop = newRetryLoop(NewRetryNTimes(3, 0), nil)
+
+	calls = 0
+
+	_, err = retryLoop.CallWithRetry(func() (interface{}, error) {
+		calls++
+
+		return nil, zk.ErrConnectionClosed
+	})
+
+	assert.EqualError(t, err, zk.ErrConnectionClosed.Error())
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 4, retryLoop.retryCount)
+}
+
+func TestRetryClassifier(t *testing.T) {
+	c := NewStandardRetryClassifier()
+
+	assert.True(t, c.IsRetryable(zk.ErrSessionExpired))
+	assert.True(t, c.IsRetryable(zk.ErrSessionMoved))
+	assert.True(t, c.IsRetryable(zk.ErrConnectionClosed))
+	assert.True(t, c.IsRetryable(ErrConnectionLoss))
+	assert.False(t, c.IsRetryable(zk.ErrNoNode))
+	assert.False(t, c.IsRetryable(zk.ErrClosing))
+	assert.True(t, c.IsRetryable(fmt.Errorf("get /node: %w", ErrConnectionLoss)))
+	assert.False(t, c.IsRetryable(fmt.Errorf("get /node: %w", zk.ErrNoNode)))
+
+	// custom classifier
+	newMockContainer().Prepare(func(builder *CuratorFrameworkBuilder) {
+		builder.RetryClassifier = NewRetryClassifier(func(err error) bool { return err == zk.ErrNoNode })
+	}).Test(t, func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, data []byte, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(nil, nil, zk.ErrNoNode).Once()
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+		conn.On("Get", "/other").Return(nil, nil, zk.ErrSessionExpired).Once()
+
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(true).Once()
+
+		result, err := client.GetData().ForPath("/node")
+
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+
+		_, err = client.GetData().ForPath("/other")
+
+		assert.Equal(t, zk.ErrSessionExpired, err)
+	})
+}
+
+func TestRetryLoopWithContext(t *testing.T) {
+	d := 3 * time.Second
+	p := NewRetryNTimes(3, d)
+	sleeper := &mockRetrySleeper{}
+	tracer := &mockTracerDriver{}
+
+	// context was done before the first attempt
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cancel()
+
+	retryLoop := newRetryLoop(p, tracer)
+
+	_, err := retryLoop.CallWithRetryContext(ctx, func() (interface{}, error) {
+		t.Error("should not be called")
+
+		return nil, nil
+	})
+
+	assert.Equal(t, context.Canceled, err)
+
+	// context was done while sleeping between retries
+	ctx, cancel = context.WithCancel(context.Background())
+
+	retryLoop = newRetryLoop(p, tracer)
+	retryLoop.retrySleeper = sleeper
+
+	sleeper.On("SleepFor", d).Return(nil).Run(func(mock.Arguments) { cancel() }).Once()
+	tracer.On("AddCount", "retries-disallowed", 1).Return().Once()
+
+	_, err = retryLoop.CallWithRetryContext(ctx, func() (interface{}, error) {
+		return nil, zk.ErrSessionExpired
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, retryLoop.retryCount)
+
+	sleeper.AssertExpectations(t)
+	tracer.AssertExpectations(t)
 }
 
 func TestRetryNTimes(t *testing.T) {
@@ -116,3 +237,112 @@ func TestRetryUntilElapsed(t *testing.T) {
 
 	s.AssertExpectations(t)
 }
+
+func TestRetryForever(t *testing.T) {
+	d := 3 * time.Second
+	p := NewRetryForever(d)
+	s := &mockRetrySleeper{}
+
+	assert.NotNil(t, p)
+
+	s.On("SleepFor", d).Return(nil).Times(3)
+
+	assert.True(t, p.AllowRetry(1, 0, s))
+	assert.True(t, p.AllowRetry(1000, 0, s))
+	assert.True(t, p.AllowRetry(MAX_RETRIES_LIMIT*1000, time.Hour, s))
+
+	s.AssertExpectations(t)
+}
+
+func TestBoundedExponentialBackoffRetry(t *testing.T) {
+	d := 3 * time.Second
+	p := NewBoundedExponentialBackoffRetry(d, 5*time.Second, 10)
+	s := &mockRetrySleeper{}
+
+	assert.NotNil(t, p)
+	assert.Equal(t, 5*time.Second, p.MaxSleepTime())
+
+	s.On("SleepFor", mock.AnythingOfType("Duration")).Return(nil).Times(10)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, p.AllowRetry(i, 0, s))
+	}
+
+	assert.False(t, p.AllowRetry(10, 0, s))
+
+	for _, call := range s.Calls {
+		assert.True(t, call.Arguments.Get(0).(time.Duration) <= 5*time.Second)
+	}
+
+	s.AssertExpectations(t)
+}
+
+func TestDecorrelatedJitterRetry(t *testing.T) {
+	d := 100 * time.Millisecond
+	p := NewDecorrelatedJitterRetry(d, time.Second, 10)
+	s := &mockRetrySleeper{}
+
+	assert.NotNil(t, p)
+
+	s.On("SleepFor", mock.AnythingOfType("Duration")).Return(nil).Times(10)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, p.AllowRetry(i, 0, s))
+	}
+
+	assert.False(t, p.AllowRetry(10, 0, s))
+
+	upper := 3 * d
+
+	for _, call := range s.Calls {
+		sleep := call.Arguments.Get(0).(time.Duration)
+
+		assert.True(t, sleep >= d)
+		assert.True(t, sleep <= time.Second)
+		assert.True(t, sleep <= upper)
+
+		upper *= 3
+	}
+
+	s.AssertExpectations(t)
+}
+
+func TestSessionFailedRetryPolicy(t *testing.T) {
+	d := 3 * time.Second
+	p := NewSessionFailedRetryPolicy(NewRetryNTimes(3, d))
+	s := &mockRetrySleeper{}
+
+	s.On("SleepFor", d).Return(nil).Twice()
+
+	assert.True(t, p.AllowRetry(0, 0, s))
+
+	p.StateChanged(nil, SUSPENDED)
+
+	assert.True(t, p.AllowRetry(1, 0, s))
+
+	// give up without sleeping
+	p.StateChanged(nil, LOST)
+
+	assert.False(t, p.AllowRetry(2, 0, s))
+
+	p.StateChanged(nil, RECONNECTED)
+
+	assert.False(t, p.AllowRetry(3, 0, s))
+
+	s.AssertExpectations(t)
+
+	// registered as a connection state listener of the framework
+	newMockContainer().Prepare(func(builder *CuratorFrameworkBuilder) {
+		builder.RetryPolicy = p
+	}).Test(t, func(client CuratorFramework) {
+		registered := false
+
+		client.ConnectionStateListenable().ForEach(func(listener interface{}) {
+			if listener == p {
+				registered = true
+			}
+		})
+
+		assert.True(t, registered)
+	})
+}
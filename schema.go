@@ -0,0 +1,317 @@
+package curator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validate the data will be written to the node
+type DataValidator func(path string, data []byte) error
+
+// The named data validators which could be referenced by the JSON schemas
+var SchemaValidators = map[string]DataValidator{
+	"json": ValidateJson,
+}
+
+// Validate the data is a well-formed JSON document
+func ValidateJson(path string, data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("invalid JSON data for %s", path)
+	}
+
+	return nil
+}
+
+// Describes the rules of the nodes which path matches the schema
+type Schema struct {
+	Name          string
+	Path          string         // the exact path or the template with "{param}" components, e.g. "/services/{name}"
+	Pattern       *regexp.Regexp // the regex pattern of the path, which must match the whole path
+	Documentation string
+	CreateModes   []CreateMode  // the allowed create modes, any mode is allowed if empty
+	CanBeWatched  bool          // the nodes can be watched
+	CanBeDeleted  bool          // the nodes can be deleted
+	MaxDataSize   int           // the max size of the data, unlimited if zero
+	Validator     DataValidator // validate the data before written
+
+	template *ZPath
+	pattern  *regexp.Regexp // the Pattern anchored to match the whole path
+}
+
+// Create a schema for the path which could be watched and deleted
+func NewSchema(name, path string) *Schema {
+	return &Schema{
+		Name:         name,
+		Path:         path,
+		CanBeWatched: true,
+		CanBeDeleted: true,
+	}
+}
+
+func (s *Schema) String() string {
+	if s.Pattern != nil {
+		return fmt.Sprintf("%s(%s)", s.Name, s.Pattern)
+	}
+
+	return fmt.Sprintf("%s(%s)", s.Name, s.Path)
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != nil {
+		if pattern, err := regexp.Compile("^(?:" + s.Pattern.String() + ")$"); err != nil {
+			return err
+		} else {
+			s.pattern = pattern
+		}
+	} else if strings.ContainsAny(s.Path, "{}") {
+		if template, err := ParseZPath(s.Path); err != nil {
+			return err
+		} else {
+			s.template = template
+		}
+	}
+
+	return nil
+}
+
+// Return true if the path matches the schema
+func (s *Schema) Matches(path string) bool {
+	if s.pattern != nil {
+		return s.pattern.MatchString(path)
+	} else if s.template != nil {
+		_, matched := s.template.Match(path)
+
+		return matched
+	}
+
+	return s.Path == path
+}
+
+// Validate the node could be created with the mode and data
+func (s *Schema) ValidateCreate(path string, mode CreateMode, data []byte) error {
+	if len(s.CreateModes) > 0 {
+		allowed := false
+
+		for _, m := range s.CreateModes {
+			if m == mode {
+				allowed = true
+
+				break
+			}
+		}
+
+		if !allowed {
+			return &SchemaViolation{s, path, fmt.Sprintf("create mode %s is not allowed", mode)}
+		}
+	}
+
+	return s.ValidateData(path, data)
+}
+
+// Validate the data could be written to the node
+func (s *Schema) ValidateData(path string, data []byte) error {
+	if s.MaxDataSize > 0 && len(data) > s.MaxDataSize {
+		return &SchemaViolation{s, path, fmt.Sprintf("data size %d exceeds the max size %d", len(data), s.MaxDataSize)}
+	}
+
+	if s.Validator != nil {
+		if err := s.Validator(path, data); err != nil {
+			return &SchemaViolation{s, path, err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// Validate the node could be watched
+func (s *Schema) ValidateWatch(path string) error {
+	if !s.CanBeWatched {
+		return &SchemaViolation{s, path, "cannot be watched"}
+	}
+
+	return nil
+}
+
+// Validate the node could be deleted
+func (s *Schema) ValidateDelete(path string) error {
+	if !s.CanBeDeleted {
+		return &SchemaViolation{s, path, "cannot be deleted"}
+	}
+
+	return nil
+}
+
+// The error returned when an operation violates the schema
+type SchemaViolation struct {
+	Schema *Schema // the violated schema, or nil if no schema matches the path
+	Path   string
+	Reason string
+}
+
+func (v *SchemaViolation) Error() string {
+	if v.Schema == nil {
+		return fmt.Sprintf("schema violation for %s: %s", v.Path, v.Reason)
+	}
+
+	return fmt.Sprintf("schema violation of %s for %s: %s", v.Schema, v.Path, v.Reason)
+}
+
+// Collection of the schemas, the first schema which matches the path is used
+type SchemaSet struct {
+	schemas      []*Schema
+	allowUnknown bool
+}
+
+// Create a schema set, the paths which don't match any schema are allowed if allowUnknown is true
+func NewSchemaSet(allowUnknown bool, schemas ...*Schema) (*SchemaSet, error) {
+	for _, schema := range schemas {
+		if err := schema.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SchemaSet{schemas: schemas, allowUnknown: allowUnknown}, nil
+}
+
+// Return the schemas of the set
+func (s *SchemaSet) Schemas() []*Schema {
+	return s.schemas
+}
+
+// Return the schema which matches the path, or nil if no schema matches and the unknown paths are allowed
+func (s *SchemaSet) GetSchema(path string) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	for _, schema := range s.schemas {
+		if schema.Matches(path) {
+			return schema, nil
+		}
+	}
+
+	if s.allowUnknown {
+		return nil, nil
+	}
+
+	return nil, &SchemaViolation{Path: path, Reason: "no schema matches the path"}
+}
+
+func (s *SchemaSet) ValidateCreate(path string, mode CreateMode, data []byte) error {
+	if schema, err := s.GetSchema(path); err != nil || schema == nil {
+		return err
+	} else {
+		return schema.ValidateCreate(path, mode, data)
+	}
+}
+
+func (s *SchemaSet) ValidateData(path string, data []byte) error {
+	if schema, err := s.GetSchema(path); err != nil || schema == nil {
+		return err
+	} else {
+		return schema.ValidateData(path, data)
+	}
+}
+
+func (s *SchemaSet) ValidateWatch(path string) error {
+	if schema, err := s.GetSchema(path); err != nil || schema == nil {
+		return err
+	} else {
+		return schema.ValidateWatch(path)
+	}
+}
+
+func (s *SchemaSet) ValidateDelete(path string) error {
+	if schema, err := s.GetSchema(path); err != nil || schema == nil {
+		return err
+	} else {
+		return schema.ValidateDelete(path)
+	}
+}
+
+type schemaSetJson struct {
+	AllowUnknown bool         `json:"allowUnknown"`
+	Schemas      []schemaJson `json:"schemas"`
+}
+
+type schemaJson struct {
+	Name          string   `json:"name"`
+	Path          string   `json:"path"`
+	Pattern       string   `json:"pattern"`
+	Documentation string   `json:"documentation"`
+	CreateModes   []string `json:"createModes"`
+	CanBeWatched  *bool    `json:"canBeWatched"`
+	CanBeDeleted  *bool    `json:"canBeDeleted"`
+	MaxDataSize   int      `json:"maxDataSize"`
+	Validator     string   `json:"validator"`
+}
+
+// Load the schema set from a JSON document, e.g.
+//
+//	{
+//		"allowUnknown": false,
+//		"schemas": [{
+//			"name": "service",
+//			"path": "/services/{name}",
+//			"createModes": ["EPHEMERAL"],
+//			"canBeDeleted": false,
+//			"maxDataSize": 1024,
+//			"validator": "json"
+//		}]
+//	}
+//
+// The validator must be registered in SchemaValidators, the nodes can be watched and deleted if not specified.
+func LoadSchemaSet(data []byte) (*SchemaSet, error) {
+	var doc schemaSetJson
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var schemas []*Schema
+
+	for _, s := range doc.Schemas {
+		schema := NewSchema(s.Name, s.Path)
+
+		schema.Documentation = s.Documentation
+		schema.MaxDataSize = s.MaxDataSize
+
+		if len(s.Pattern) > 0 {
+			if pattern, err := regexp.Compile(s.Pattern); err != nil {
+				return nil, err
+			} else {
+				schema.Pattern = pattern
+			}
+		}
+
+		for _, name := range s.CreateModes {
+			if mode, err := ParseCreateMode(name); err != nil {
+				return nil, err
+			} else {
+				schema.CreateModes = append(schema.CreateModes, mode)
+			}
+		}
+
+		if s.CanBeWatched != nil {
+			schema.CanBeWatched = *s.CanBeWatched
+		}
+
+		if s.CanBeDeleted != nil {
+			schema.CanBeDeleted = *s.CanBeDeleted
+		}
+
+		if len(s.Validator) > 0 {
+			if validator, exists := SchemaValidators[s.Validator]; exists {
+				schema.Validator = validator
+			} else {
+				return nil, fmt.Errorf("unknown validator: %s", s.Validator)
+			}
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	return NewSchemaSet(doc.AllowUnknown, schemas...)
+}
@@ -0,0 +1,189 @@
+package curator
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSchemaMatches(t *testing.T) {
+	schemas, err := NewSchemaSet(false,
+		NewSchema("exact", "/config"),
+		NewSchema("template", "/services/{name}/instances/{id}"),
+		&Schema{Name: "regex", Pattern: regexp.MustCompile(`/locks/lock-\d+`)},
+		&Schema{Name: "alternation", Pattern: regexp.MustCompile(`/queue/item|/queue/item-\d+`)},
+	)
+
+	assert.NoError(t, err)
+
+	for path, name := range map[string]string{
+		"/config":                        "exact",
+		"/services/web/instances/node-1": "template",
+		"/locks/lock-0000000001":         "regex",
+		"/queue/item-0000000001":         "alternation",
+	} {
+		schema, err := schemas.GetSchema(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, name, schema.Name)
+	}
+
+	for _, path := range []string{"/config/child", "/services/web/instances", "/services/web/instances/a/b", "/locks/lock-1a", "/prefix/locks/lock-1"} {
+		schema, err := schemas.GetSchema(path)
+
+		assert.Nil(t, schema)
+		assert.EqualError(t, err, "schema violation for "+path+": no schema matches the path")
+	}
+
+	_, err = NewSchemaSet(true, NewSchema("invalid", "/services/{name"))
+
+	assert.EqualError(t, err, "invalid path template: /services/{name")
+}
+
+func TestSchemaRules(t *testing.T) {
+	schema := &Schema{
+		Name:         "service",
+		Path:         "/service",
+		CreateModes:  []CreateMode{EPHEMERAL, EPHEMERAL_SEQUENTIAL},
+		CanBeWatched: true,
+		MaxDataSize:  8,
+		Validator:    ValidateJson,
+	}
+
+	assert.NoError(t, schema.ValidateCreate("/service", EPHEMERAL, []byte("{}")))
+	assert.EqualError(t, schema.ValidateCreate("/service", PERSISTENT, []byte("{}")),
+		"schema violation of service(/service) for /service: create mode PERSISTENT is not allowed")
+	assert.EqualError(t, schema.ValidateData("/service", []byte(`{"a":"b"}`)),
+		"schema violation of service(/service) for /service: data size 9 exceeds the max size 8")
+	assert.EqualError(t, schema.ValidateData("/service", []byte("{")),
+		"schema violation of service(/service) for /service: invalid JSON data for /service")
+	assert.NoError(t, schema.ValidateWatch("/service"))
+	assert.EqualError(t, schema.ValidateDelete("/service"),
+		"schema violation of service(/service) for /service: cannot be deleted")
+
+	var schemas *SchemaSet
+
+	assert.NoError(t, schemas.ValidateDelete("/service"))
+}
+
+func TestLoadSchemaSet(t *testing.T) {
+	schemas, err := LoadSchemaSet([]byte(`{
+		"allowUnknown": true,
+		"schemas": [{
+			"name": "service",
+			"path": "/services/{name}",
+			"documentation": "the registered services",
+			"createModes": ["EPHEMERAL", "EPHEMERAL_SEQUENTIAL"],
+			"canBeDeleted": false,
+			"maxDataSize": 1024,
+			"validator": "json"
+		}, {
+			"name": "locks",
+			"pattern": "/locks/.*",
+			"canBeWatched": false
+		}]
+	}`))
+
+	assert.NoError(t, err)
+	assert.Len(t, schemas.Schemas(), 2)
+
+	schema, err := schemas.GetSchema("/services/web")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "service", schema.Name)
+	assert.Equal(t, "the registered services", schema.Documentation)
+	assert.Equal(t, []CreateMode{EPHEMERAL, EPHEMERAL_SEQUENTIAL}, schema.CreateModes)
+	assert.True(t, schema.CanBeWatched)
+	assert.False(t, schema.CanBeDeleted)
+	assert.Equal(t, 1024, schema.MaxDataSize)
+	assert.NotNil(t, schema.Validator)
+
+	schema, err = schemas.GetSchema("/locks/lock-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "locks", schema.Name)
+	assert.False(t, schema.CanBeWatched)
+	assert.True(t, schema.CanBeDeleted)
+
+	schema, err = schemas.GetSchema("/unknown")
+
+	assert.Nil(t, schema)
+	assert.NoError(t, err)
+
+	_, err = LoadSchemaSet([]byte(`{"schemas": [{"name": "invalid", "path": "/", "createModes": ["UNKNOWN"]}]}`))
+
+	assert.EqualError(t, err, "unknown create mode: UNKNOWN")
+
+	_, err = LoadSchemaSet([]byte(`{"schemas": [{"name": "invalid", "path": "/", "validator": "unknown"}]}`))
+
+	assert.EqualError(t, err, "unknown validator: unknown")
+}
+
+type SchemaSetTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestSchemaSet(t *testing.T) {
+	suite.Run(t, new(SchemaSetTestSuite))
+}
+
+func (s *SchemaSetTestSuite) WithSchemas(callback interface{}) {
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.SchemaSet, _ = NewSchemaSet(true, &Schema{
+			Name:        "service",
+			Path:        "/services/{name}",
+			CreateModes: []CreateMode{EPHEMERAL},
+			MaxDataSize: 4,
+		})
+	}, callback)
+}
+
+func (s *SchemaSetTestSuite) TestCreate() {
+	s.WithSchemas(func(client CuratorFramework, conn *mockConn) {
+		_, err := client.Create().ForPathWithData("/services/web", []byte("data"))
+
+		assert.IsType(s.T(), &SchemaViolation{}, err)
+
+		_, err = client.Create().WithMode(EPHEMERAL).ForPathWithData("/services/web", []byte("large"))
+
+		assert.IsType(s.T(), &SchemaViolation{}, err)
+
+		_, err = client.SetData().ForPathWithData("/services/web", []byte("large"))
+
+		assert.IsType(s.T(), &SchemaViolation{}, err)
+	})
+}
+
+func (s *SchemaSetTestSuite) TestDeleteAndWatch() {
+	s.WithSchemas(func(client CuratorFramework, conn *mockConn) {
+		err := client.Delete().ForPath("/services/web")
+
+		assert.EqualError(s.T(), err, "schema violation of service(/services/{name}) for /services/web: cannot be deleted")
+
+		_, err = client.GetData().Watched().ForPath("/services/web")
+
+		assert.EqualError(s.T(), err, "schema violation of service(/services/{name}) for /services/web: cannot be watched")
+
+		_, err = client.CheckExists().Watched().ForPath("/services/web")
+
+		assert.IsType(s.T(), &SchemaViolation{}, err)
+
+		_, err = client.GetChildren().Watched().ForPath("/services/web")
+
+		assert.IsType(s.T(), &SchemaViolation{}, err)
+	})
+}
+
+func (s *SchemaSetTestSuite) TestTransaction() {
+	s.WithSchemas(func(client CuratorFramework, conn *mockConn, acls []zk.ACL) {
+		_, err := client.InTransaction().
+			Create().WithMode(EPHEMERAL).WithACL(acls...).ForPath("/services/web").
+			Delete().ForPath("/services/db").
+			Commit()
+
+		assert.EqualError(s.T(), err, "schema violation of service(/services/{name}) for /services/web: data size 7 exceeds the max size 4")
+	})
+}
@@ -0,0 +1,118 @@
+package curator
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	Serializers = map[string]Serializer{
+		"json":  NewJsonSerializer(),
+		"gob":   NewGobSerializer(),
+		"proto": NewProtoSerializer(),
+		"raw":   NewRawSerializer(),
+	}
+)
+
+// Convert the values to and from the node data
+type Serializer interface {
+	Serialize(path string, value interface{}) ([]byte, error)
+
+	Deserialize(path string, data []byte, value interface{}) error
+}
+
+type JsonSerializer struct{}
+
+func NewJsonSerializer() *JsonSerializer {
+	return &JsonSerializer{}
+}
+
+func (s *JsonSerializer) Serialize(path string, value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (s *JsonSerializer) Deserialize(path string, data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+type GobSerializer struct{}
+
+func NewGobSerializer() *GobSerializer {
+	return &GobSerializer{}
+}
+
+func (s *GobSerializer) Serialize(path string, value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *GobSerializer) Deserialize(path string, data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// The protobuf message which could marshal itself, e.g. generated by gogo/protobuf
+type ProtoMessage interface {
+	Marshal() ([]byte, error)
+
+	Unmarshal(data []byte) error
+}
+
+type ProtoSerializer struct{}
+
+func NewProtoSerializer() *ProtoSerializer {
+	return &ProtoSerializer{}
+}
+
+func (s *ProtoSerializer) Serialize(path string, value interface{}) ([]byte, error) {
+	if msg, ok := value.(ProtoMessage); ok {
+		return msg.Marshal()
+	}
+
+	return nil, fmt.Errorf("%T is not a protobuf message", value)
+}
+
+func (s *ProtoSerializer) Deserialize(path string, data []byte, value interface{}) error {
+	if msg, ok := value.(ProtoMessage); ok {
+		return msg.Unmarshal(data)
+	}
+
+	return fmt.Errorf("%T is not a protobuf message", value)
+}
+
+// Pass the []byte or string values through without any conversion
+type RawSerializer struct{}
+
+func NewRawSerializer() *RawSerializer {
+	return &RawSerializer{}
+}
+
+func (s *RawSerializer) Serialize(path string, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%T is not a raw value", value)
+	}
+}
+
+func (s *RawSerializer) Deserialize(path string, data []byte, value interface{}) error {
+	switch v := value.(type) {
+	case *[]byte:
+		*v = data
+	case *string:
+		*v = string(data)
+	default:
+		return fmt.Errorf("%T is not a raw value", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package curator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testValue struct {
+	Name  string
+	Count int
+}
+
+func TestJsonSerializer(t *testing.T) {
+	s := NewJsonSerializer()
+
+	data, err := s.Serialize("/node", &testValue{"test", 123})
+
+	assert.Equal(t, `{"Name":"test","Count":123}`, string(data))
+	assert.NoError(t, err)
+
+	var value testValue
+
+	assert.NoError(t, s.Deserialize("/node", data, &value))
+	assert.Equal(t, testValue{"test", 123}, value)
+}
+
+func TestGobSerializer(t *testing.T) {
+	s := NewGobSerializer()
+
+	data, err := s.Serialize("/node", &testValue{"test", 123})
+
+	assert.NotEmpty(t, data)
+	assert.NoError(t, err)
+
+	var value testValue
+
+	assert.NoError(t, s.Deserialize("/node", data, &value))
+	assert.Equal(t, testValue{"test", 123}, value)
+}
+
+type testMessage struct {
+	data []byte
+}
+
+func (m *testMessage) Marshal() ([]byte, error) {
+	if m.data == nil {
+		return nil, errors.New("empty message")
+	}
+
+	return m.data, nil
+}
+
+func (m *testMessage) Unmarshal(data []byte) error {
+	m.data = data
+
+	return nil
+}
+
+func TestProtoSerializer(t *testing.T) {
+	s := NewProtoSerializer()
+
+	data, err := s.Serialize("/node", &testMessage{[]byte("data")})
+
+	assert.Equal(t, "data", string(data))
+	assert.NoError(t, err)
+
+	var msg testMessage
+
+	assert.NoError(t, s.Deserialize("/node", data, &msg))
+	assert.Equal(t, "data", string(msg.data))
+
+	_, err = s.Serialize("/node", &testMessage{})
+
+	assert.EqualError(t, err, "empty message")
+
+	_, err = s.Serialize("/node", "data")
+
+	assert.EqualError(t, err, "string is not a protobuf message")
+}
+
+func TestRawSerializer(t *testing.T) {
+	s := NewRawSerializer()
+
+	data, err := s.Serialize("/node", "data")
+
+	assert.Equal(t, "data", string(data))
+	assert.NoError(t, err)
+
+	var buf []byte
+	var str string
+
+	assert.NoError(t, s.Deserialize("/node", data, &buf))
+	assert.Equal(t, "data", string(buf))
+	assert.NoError(t, s.Deserialize("/node", data, &str))
+	assert.Equal(t, "data", str)
+
+	_, err = s.Serialize("/node", 123)
+
+	assert.EqualError(t, err, "int is not a raw value")
+}
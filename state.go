@@ -1,9 +1,9 @@
 package curator
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -132,10 +132,18 @@ type connectionState struct {
 	connectionStart   time.Time
 	isConnected       AtomicBool
 	backgroundErrors  chan error
+	persistentWatches *persistentWatches
+	watches           *watchRegistry
+	dispatcher        *SerialExecutor // deliver the events of each watch and the session in the order received
+	logger            Logger
 }
 
 func newConnectionState(zookeeperDialer ZookeeperDialer, ensembleProvider EnsembleProvider, sessionTimeout, connectionTimeout time.Duration,
-	parentWatcher Watcher, tracer TracerDriver, canBeReadOnly bool) *connectionState {
+	parentWatcher Watcher, tracer TracerDriver, canBeReadOnly bool, dispatcher *SerialExecutor) *connectionState {
+
+	if dispatcher == nil {
+		dispatcher = NewSerialExecutor(DEFAULT_DISPATCH_QUEUE_SIZE, OVERFLOW_BLOCK, nil)
+	}
 
 	s := &connectionState{
 		ensembleProvider:  ensembleProvider,
@@ -145,8 +153,13 @@ func newConnectionState(zookeeperDialer ZookeeperDialer, ensembleProvider Ensemb
 		parentWatchers:    NewWatchers(),
 		connectionStart:   time.Now(),
 		backgroundErrors:  make(chan error, MAX_BACKGROUND_ERRORS),
+		persistentWatches: newPersistentWatches(),
+		dispatcher:        dispatcher,
+		logger:            DefaultLogger,
 	}
 
+	s.watches = newWatchRegistry(dispatcher, s.traceEvent)
+
 	if zookeeperDialer == nil {
 		zookeeperDialer = &DefaultZookeeperDialer{}
 	}
@@ -196,6 +209,49 @@ func (s *connectionState) Start() error {
 	return s.reset()
 }
 
+// Return the id of the current session if the connection reports it, otherwise 0
+func (s *connectionState) sessionId() int64 {
+	if cache, ok := s.zooKeeper.helper.(*zookeeperCache); ok {
+		if conn, ok := cache.conn.(interface{ SessionID() int64 }); ok {
+			return conn.SessionID() // e.g. *zk.Conn, which doesn't report the negotiated session timeout
+		}
+	}
+
+	return 0
+}
+
+// Return the logger with the fields of the current connection
+func (s *connectionState) sessionLogger() Logger {
+	return s.logger.With(LOG_KEY_CONNECT_STRING, s.zooKeeper.getConnectionString(), LOG_KEY_SESSION_ID, fmt.Sprintf("0x%x", s.sessionId()))
+}
+
+// Record the trace of the watched event if the tracer is an AdvancedTracerDriver
+func (s *connectionState) traceEvent(event *zk.Event) {
+	if driver, ok := s.tracer.(AdvancedTracerDriver); ok {
+		driver.AddEventTrace(&EventTrace{
+			Name:      "watched-event",
+			Path:      event.Path,
+			EventType: event.Type.String(),
+			State:     event.State.String(),
+			SessionId: s.sessionId(),
+			Time:      time.Now(),
+		})
+	}
+}
+
+// Return the session timeout negotiated with the server if the connection reports it, otherwise the requested one
+func (s *connectionState) negotiatedSessionTimeout() time.Duration {
+	if cache, ok := s.zooKeeper.helper.(*zookeeperCache); ok {
+		if conn, ok := cache.conn.(SessionZookeeperConnection); ok {
+			if timeout := conn.SessionTimeout(); timeout > 0 {
+				return timeout
+			}
+		}
+	}
+
+	return s.sessionTimeout
+}
+
 func (s *connectionState) Close() error {
 	CloseQuietly(s.ensembleProvider)
 
@@ -203,6 +259,8 @@ func (s *connectionState) Close() error {
 
 	s.isConnected.Set(false)
 
+	s.dispatcher.Close()
+
 	return err
 }
 
@@ -213,7 +271,13 @@ func (s *connectionState) reset() error {
 
 	s.zooKeeper.closeAndReset()
 
-	_, err := s.zooKeeper.getZookeeperConnection() // initiate connection
+	conn, err := s.zooKeeper.getZookeeperConnection() // initiate connection
+
+	if err == nil && conn != nil {
+		if err := s.reregisterPersistentWatches(conn); err != nil {
+			s.queueBackgroundException(err)
+		}
+	}
 
 	return err
 }
@@ -226,6 +290,119 @@ func (s *connectionState) RemoveParentWatcher(watcher Watcher) Watcher {
 	return s.parentWatchers.Remove(watcher)
 }
 
+func (s *connectionState) addPersistentWatch(conn ZookeeperConnection, path string, mode AddWatchMode, watcher Watcher) error {
+	s.persistentWatches.lock.Lock()
+	defer s.persistentWatches.lock.Unlock()
+
+	watch, exists := s.persistentWatches.watches[path]
+
+	if !exists || watch.mode != mode {
+		watchers := NewWatchers()
+
+		if exists {
+			watchers = watch.watchers
+		}
+
+		if err := s.registerPersistentWatch(conn, &persistentWatch{path: path, mode: mode, watchers: watchers}); err != nil {
+			return err
+		}
+	}
+
+	if watcher != nil {
+		s.persistentWatches.watches[path].watchers.Add(watcher)
+	}
+
+	return nil
+}
+
+// Remove the watcher of the persistent watch, or the whole persistent watch if the watcher is nil.
+// Return the number of the removed watches.
+func (s *connectionState) removePersistentWatch(path string, watcher Watcher) int {
+	s.persistentWatches.lock.Lock()
+	defer s.persistentWatches.lock.Unlock()
+
+	watch, exists := s.persistentWatches.watches[path]
+
+	if !exists {
+		return 0
+	}
+
+	if watcher != nil {
+		if watch.watchers.Remove(watcher) == nil {
+			return 0
+		}
+
+		return 1
+	}
+
+	watch.removed.Set(true)
+
+	delete(s.persistentWatches.watches, path)
+
+	return 1
+}
+
+// Remove the one-time and persistent watches on the path, which match the type and the watcher or any watcher if it is nil.
+// Return the number of the removed watches.
+func (s *connectionState) removeWatches(path string, watcherType WatcherType, watcher Watcher) int {
+	removed := s.watches.remove(path, watcherType, watcher)
+
+	if watcherType == WATCHER_ANY {
+		removed += s.removePersistentWatch(path, watcher)
+	}
+
+	return removed
+}
+
+func (s *connectionState) reregisterPersistentWatches(conn ZookeeperConnection) error {
+	s.persistentWatches.lock.Lock()
+	defer s.persistentWatches.lock.Unlock()
+
+	for _, watch := range s.persistentWatches.watches {
+		if err := s.registerPersistentWatch(conn, &persistentWatch{path: watch.path, mode: watch.mode, watchers: watch.watchers}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *connectionState) registerPersistentWatch(conn ZookeeperConnection, watch *persistentWatch) error {
+	watchesConn, ok := conn.(WatchesZookeeperConnection)
+
+	if !ok {
+		return ErrWatchesNotSupported
+	}
+
+	events, err := watchesConn.AddWatch(watch.path, int32(watch.mode))
+
+	if err != nil {
+		return err
+	}
+
+	if old, exists := s.persistentWatches.watches[watch.path]; exists {
+		old.removed.Set(true)
+	}
+
+	s.persistentWatches.watches[watch.path] = watch
+
+	go func() {
+		for {
+			if event, ok := <-events; !ok || watch.removed.Load() {
+				break
+			} else {
+				s.dispatcher.Execute(func() {
+					watch.watchers.fire(&event)
+
+					s.process(&event)
+				})
+			}
+		}
+	}()
+
+	return nil
+}
+
 func (s *connectionState) checkTimeout() error {
 	var minTimeout, maxTimeout time.Duration
 
@@ -243,13 +420,13 @@ func (s *connectionState) checkTimeout() error {
 		if s.zooKeeper.hasNewConnectionString() {
 			s.handleNewConnectionString()
 		} else if elapsed >= maxTimeout {
-			log.Printf("Connection attempt unsuccessful after %v (greater than max timeout of %v). Resetting connection and trying again with a new connection.", elapsed, maxTimeout)
+			s.sessionLogger().Warn("Connection attempt unsuccessful, resetting connection and trying again with a new connection", "elapsed", elapsed, "maxTimeout", maxTimeout)
 
 			s.tracer.AddCount("session-timed-out", 1)
 
 			return s.reset()
 		} else {
-			log.Printf("Connection timed out for connection string (%s) and timeout (%v) / elapsed (%v)", s.zooKeeper.getConnectionString(), s.connectionTimeout, elapsed)
+			s.sessionLogger().Warn("Connection timed out", "connectionTimeout", s.connectionTimeout, "elapsed", elapsed)
 
 			s.tracer.AddCount("connections-timed-out", 1)
 
@@ -260,18 +437,12 @@ func (s *connectionState) checkTimeout() error {
 	return nil
 }
 
+// Called in the goroutine receiving the session events,
+// update the connection state before the parent watchers are called through the dispatcher.
 func (s *connectionState) process(event *zk.Event) {
-	//log.Printf("connectionState.process received %v with %d watchers", event, s.parentWatchers.Len())
-
-	for _, watcher := range s.parentWatchers.watchers {
-		go func() {
-			tracer := newTimeTracer("connection-state-parent-process", s.tracer)
+	s.logger.Debug("Connection state received event", LOG_KEY_PATH, event.Path, "type", event.Type, "state", event.State)
 
-			defer tracer.Commit()
-
-			watcher.process(event)
-		}()
-	}
+	s.traceEvent(event)
 
 	if event.Type == zk.EventSession {
 		wasConnected := s.isConnected.Load()
@@ -281,6 +452,16 @@ func (s *connectionState) process(event *zk.Event) {
 			s.connectionStart = time.Now()
 		}
 	}
+
+	execute(s.dispatcher, func() {
+		for _, watcher := range s.parentWatchers.snapshot() {
+			tracer := newTimeTracer("connection-state-parent-process", s.tracer)
+
+			watcher.process(event)
+
+			tracer.Commit()
+		}
+	})
 }
 
 func (s *connectionState) checkState(state zk.State, err error, wasConnected bool) bool {
@@ -314,7 +495,7 @@ func (s *connectionState) checkState(state zk.State, err error, wasConnected boo
 }
 
 func (s *connectionState) handleNewConnectionString() {
-	log.Print("Connection string changed")
+	s.sessionLogger().Info("Connection string changed")
 
 	s.tracer.AddCount("connection-string-changed", 1)
 
@@ -323,8 +504,20 @@ func (s *connectionState) handleNewConnectionString() {
 	}
 }
 
+// Expire the session locally by closing the connection and starting a new session,
+// which is used when the server can't be reached to expire the session.
+func (s *connectionState) expireSession() {
+	s.sessionLogger().Info("Injecting session expiration")
+
+	s.tracer.AddCount("session-expired", 1)
+
+	if err := s.reset(); err != nil {
+		s.queueBackgroundException(err)
+	}
+}
+
 func (s *connectionState) handleExpiredSession() {
-	log.Print("Session expired event received")
+	s.sessionLogger().Info("Session expired event received")
 
 	s.tracer.AddCount("session-expired", 1)
 
@@ -386,7 +579,38 @@ func (s ConnectionState) String() string {
 	return connectionStateNames[s]
 }
 
-const STATE_QUEUE_SIZE = 25
+// Recipes should use the configured error policy to decide how to handle errors such as ConnectionState changes.
+type ConnectionStateErrorPolicy interface {
+	// Return true if the given state represents an error
+	IsErrorState(state ConnectionState) bool
+}
+
+// This policy treats only LOST as an error
+type SessionConnectionStateErrorPolicy struct{}
+
+func NewSessionConnectionStateErrorPolicy() *SessionConnectionStateErrorPolicy {
+	return &SessionConnectionStateErrorPolicy{}
+}
+
+func (p *SessionConnectionStateErrorPolicy) IsErrorState(state ConnectionState) bool {
+	return state == LOST
+}
+
+// This policy treats SUSPENDED and LOST as errors
+type StandardConnectionStateErrorPolicy struct{}
+
+func NewStandardConnectionStateErrorPolicy() *StandardConnectionStateErrorPolicy {
+	return &StandardConnectionStateErrorPolicy{}
+}
+
+func (p *StandardConnectionStateErrorPolicy) IsErrorState(state ConnectionState) bool {
+	return state == SUSPENDED || state == LOST
+}
+
+const (
+	STATE_QUEUE_SIZE                   = 25
+	DEFAULT_SESSION_EXPIRATION_PERCENT = 100
+)
 
 type connectionStateManager struct {
 	client                    CuratorFramework
@@ -397,13 +621,23 @@ type connectionStateManager struct {
 	initialConnectMessageSent AtomicBool
 	events                    chan ConnectionState
 	QueueSize                 int
+	SessionExpirationPercent  int                  // the percent of the session timeout to wait in SUSPENDED before emulating LOST, negative to disable
+	sessionTimeout            func() time.Duration // return the negotiated session timeout
+	expireSession             func()               // expire the session locally and start a new one
+	suspendedTimer            *time.Timer
+	suspendedEpoch            int // incremented when the suspended timer is stopped
+	errorHandler              *callbackErrorHandler
+	logger                    Logger
+	tracer                    TracerDriver
 }
 
 func newConnectionStateManager(client CuratorFramework) *connectionStateManager {
 	return &connectionStateManager{
-		client:    client,
-		listeners: new(connectionStateListenerContainer),
-		QueueSize: STATE_QUEUE_SIZE,
+		client:                   client,
+		listeners:                new(connectionStateListenerContainer),
+		QueueSize:                STATE_QUEUE_SIZE,
+		SessionExpirationPercent: DEFAULT_SESSION_EXPIRATION_PERCENT,
+		logger:                   DefaultLogger,
 	}
 }
 
@@ -424,6 +658,10 @@ func (m *connectionStateManager) Close() {
 		return
 	}
 
+	m.lock.Lock()
+	m.stopSuspendedTimer()
+	m.lock.Unlock()
+
 	close(m.events)
 
 	m.listeners.Clear()
@@ -446,7 +684,7 @@ func (m *connectionStateManager) SetToSuspended() bool {
 		return false
 	}
 
-	m.currentConnectionState = SUSPENDED
+	m.setConnectionState(SUSPENDED)
 
 	m.postState(SUSPENDED)
 
@@ -467,7 +705,7 @@ func (m *connectionStateManager) AddStateChange(newConnectionState ConnectionSta
 		return false
 	}
 
-	m.currentConnectionState = newConnectionState
+	m.setConnectionState(newConnectionState)
 
 	localState := newConnectionState
 
@@ -485,18 +723,96 @@ func (m *connectionStateManager) AddStateChange(newConnectionState ConnectionSta
 	return true
 }
 
+// Change the current state and schedule the session expiration if the connection has been suspended.
+// Must be called with the lock held.
+func (m *connectionStateManager) setConnectionState(newConnectionState ConnectionState) {
+	m.currentConnectionState = newConnectionState
+
+	m.stopSuspendedTimer()
+
+	if newConnectionState != SUSPENDED || m.SessionExpirationPercent <= 0 || m.sessionTimeout == nil {
+		return
+	}
+
+	sessionTimeout := m.sessionTimeout()
+
+	if sessionTimeout <= 0 {
+		return
+	}
+
+	epoch := m.suspendedEpoch
+
+	m.suspendedTimer = time.AfterFunc(sessionTimeout*time.Duration(m.SessionExpirationPercent)/100, func() {
+		m.emulateSessionExpired(epoch, sessionTimeout)
+	})
+}
+
+func (m *connectionStateManager) stopSuspendedTimer() {
+	m.suspendedEpoch++
+
+	if m.suspendedTimer != nil {
+		m.suspendedTimer.Stop()
+		m.suspendedTimer = nil
+	}
+}
+
+// The server can't be reached to expire the session,
+// treat the connection as LOST when it has been suspended for the configured part of the session timeout,
+// and expire the session locally, so the client won't resume the session which is probably expired on the server.
+func (m *connectionStateManager) emulateSessionExpired(epoch int, sessionTimeout time.Duration) {
+	m.lock.Lock()
+
+	if m.suspendedEpoch != epoch || m.currentConnectionState != SUSPENDED || m.state.Value() != STARTED {
+		m.lock.Unlock()
+
+		return
+	}
+
+	m.logger.Warn("Session timeout has elapsed while SUSPENDED, posting LOST event and injecting session expiration",
+		"sessionExpirationPercent", m.SessionExpirationPercent, "sessionTimeout", sessionTimeout)
+
+	m.stopSuspendedTimer()
+	m.currentConnectionState = LOST
+
+	m.postState(LOST)
+
+	m.lock.Unlock()
+
+	// closing the connection may post the state changes, which need the lock
+	if m.expireSession != nil {
+		m.expireSession()
+	}
+}
+
 func (m *connectionStateManager) BlockUntilConnected(maxWaitTime time.Duration) error {
-	if m.currentConnectionState.Connected() {
-		return nil
+	if maxWaitTime > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), maxWaitTime)
+
+		defer cancel()
+
+		if err := m.BlockUntilConnectedWithContext(ctx); err == context.DeadlineExceeded {
+			return ErrTimeout
+		} else {
+			return err
+		}
 	}
 
-	c := make(chan ConnectionState)
+	return m.BlockUntilConnectedWithContext(context.Background())
+}
 
-	defer close(c)
+func (m *connectionStateManager) BlockUntilConnectedWithContext(ctx context.Context) error {
+	if m.Connected() {
+		return nil
+	}
+
+	c := make(chan ConnectionState, 1)
 
 	listener := NewConnectionStateListener(func(client CuratorFramework, newState ConnectionState) {
 		if newState.Connected() {
-			c <- newState
+			select {
+			case c <- newState:
+			default:
+			}
 		}
 	})
 
@@ -504,19 +820,15 @@ func (m *connectionStateManager) BlockUntilConnected(maxWaitTime time.Duration)
 
 	defer m.listeners.RemoveListener(listener)
 
-	if maxWaitTime > 0 {
-		timer := time.NewTimer(maxWaitTime)
-
-		select {
-		case <-c:
-			return nil
-		case <-timer.C:
-			return ErrTimeout
-		}
-	} else {
-		<-c
+	if m.Connected() {
+		return nil
+	}
 
+	select {
+	case <-c:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -528,6 +840,10 @@ func (m *connectionStateManager) Connected() bool {
 }
 
 func (m *connectionStateManager) postState(state ConnectionState) {
+	if driver, ok := m.tracer.(AdvancedTracerDriver); ok {
+		driver.AddEventTrace(&EventTrace{Name: "connection-state-change", State: state.String(), Time: time.Now()})
+	}
+
 	defer func() {
 		recover() // channel closed
 	}()
@@ -556,7 +872,13 @@ func (m *connectionStateManager) processEvents() {
 			return // queue closed
 		} else {
 			m.listeners.ForEach(func(listener interface{}) {
-				listener.(ConnectionStateListener).StateChanged(m.client, newState)
+				info := CallbackError{Source: "ConnectionStateListener", Operation: "StateChanged", EventType: newState.String()}
+
+				m.errorHandler.callListener(listener, info, func(listener interface{}) error {
+					listener.(ConnectionStateListener).StateChanged(m.client, newState)
+
+					return nil
+				})
 			})
 		}
 	}
@@ -1,6 +1,7 @@
 package curator
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -63,6 +64,29 @@ func TestHandleHolder(t *testing.T) {
 	zookeeperConnection.AssertExpectations(t)
 }
 
+// The connection which only reports the session id, like *zk.Conn
+type sessionIdConn struct {
+	*mockConn
+
+	sessionId int64
+}
+
+func (c *sessionIdConn) SessionID() int64 { return c.sessionId }
+
+func TestSessionId(t *testing.T) {
+	s := newConnectionState(nil, nil, 15*time.Second, 5*time.Second, nil, nil, false, nil)
+
+	assert.Equal(t, int64(0), s.sessionId())
+
+	s.zooKeeper.helper = &zookeeperCache{"connStr", &mockConn{log: t.Logf}}
+
+	assert.Equal(t, int64(0), s.sessionId())
+
+	s.zooKeeper.helper = &zookeeperCache{"connStr", &sessionIdConn{&mockConn{log: t.Logf}, 0x1234}}
+
+	assert.Equal(t, int64(0x1234), s.sessionId())
+}
+
 type ConnectionStateTestSuite struct {
 	suite.Suite
 
@@ -75,6 +99,7 @@ type ConnectionStateTestSuite struct {
 	canBeReadOnly     bool
 	events            chan zk.Event
 	watcher           Watcher
+	lock              sync.Mutex
 	sessionEvents     []*zk.Event
 	state             *connectionState
 	connStrTimes      int
@@ -101,11 +126,14 @@ func (s *ConnectionStateTestSuite) SetupTest() {
 	s.connCloseTimes = 1
 
 	s.watcher = NewWatcher(func(event *zk.Event) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
 		s.sessionEvents = append(s.sessionEvents, event)
 	})
 
 	// create connection
-	s.state = newConnectionState(s.zookeeperDialer, s.ensembleProvider, s.sessionTimeout, s.connectionTimeout, s.watcher, s.tracer, s.canBeReadOnly)
+	s.state = newConnectionState(s.zookeeperDialer, s.ensembleProvider, s.sessionTimeout, s.connectionTimeout, s.watcher, s.tracer, s.canBeReadOnly, nil)
 
 	assert.NotNil(s.T(), s.state)
 	assert.False(s.T(), s.state.Connected())
@@ -129,6 +157,29 @@ func (s *ConnectionStateTestSuite) Close() {
 	assert.NoError(s.T(), s.state.Close())
 }
 
+// Return the session events received by the parent watcher
+func (s *ConnectionStateTestSuite) received() []*zk.Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*zk.Event(nil), s.sessionEvents...)
+}
+
+// Wait until the parent watcher received the given number of the session events,
+// which are delivered through the dispatcher after the connection state was updated.
+func (s *ConnectionStateTestSuite) waitForEvents(count int) {
+	assert.Eventually(s.T(), func() bool { return len(s.received()) == count }, time.Second, time.Millisecond)
+}
+
+// Wait until the tasks queued in the dispatcher have been executed
+func (s *ConnectionStateTestSuite) flush() {
+	done := make(chan struct{})
+
+	s.state.dispatcher.Execute(func() { close(done) })
+
+	<-done
+}
+
 func (s *ConnectionStateTestSuite) TearDownTest() {
 	s.ensembleProvider.AssertExpectations(s.T())
 	s.zookeeperDialer.AssertExpectations(s.T())
@@ -254,7 +305,7 @@ func (s *ConnectionStateTestSuite) TestConnected() {
 		State: zk.StateHasSession,
 	}
 
-	time.Sleep(100 * time.Microsecond)
+	s.waitForEvents(1)
 
 	assert.True(s.T(), s.state.Connected())
 }
@@ -280,7 +331,7 @@ func (s *ConnectionStateTestSuite) TestNewConnectionString() {
 		State: zk.StateHasSession,
 	}
 
-	time.Sleep(100 * time.Microsecond)
+	s.waitForEvents(1)
 
 	assert.Equal(s.T(), instanceIndex+1, s.state.InstanceIndex())
 	assert.False(s.T(), s.state.Connected())
@@ -310,7 +361,7 @@ func (s *ConnectionStateTestSuite) TestExpiredSession() {
 		State: zk.StateHasSession,
 	}
 
-	time.Sleep(100 * time.Microsecond)
+	s.waitForEvents(1)
 
 	assert.True(s.T(), s.state.Connected())
 
@@ -322,7 +373,7 @@ func (s *ConnectionStateTestSuite) TestExpiredSession() {
 		State: zk.StateExpired,
 	}
 
-	time.Sleep(100 * time.Microsecond)
+	s.waitForEvents(2)
 
 	assert.Equal(s.T(), instanceIndex+1, s.state.InstanceIndex())
 	assert.False(s.T(), s.state.Connected())
@@ -343,25 +394,24 @@ func (s *ConnectionStateTestSuite) TestParentWatcher() {
 	// receive a session event
 	s.tracer.On("AddTime", "connection-state-parent-process", mock.AnythingOfType("Duration")).Return().Twice()
 
-	assert.Nil(s.T(), s.sessionEvents)
+	assert.Empty(s.T(), s.received())
 
 	s.events <- zk.Event{
 		Type:  zk.EventSession,
 		State: zk.StateConnecting,
 	}
 
-	time.Sleep(100 * time.Microsecond)
-
-	assert.Equal(s.T(), 1, len(s.sessionEvents))
+	s.waitForEvents(1)
 
 	assert.Equal(s.T(), s.state.RemoveParentWatcher(s.watcher), s.watcher)
 
-	s.events <- zk.Event{
+	// process the event in place, so it has been queued before the watcher is added back
+	s.state.process(&zk.Event{
 		Type:  zk.EventSession,
 		State: zk.StateConnected,
-	}
+	})
 
-	time.Sleep(100 * time.Microsecond)
+	s.flush()
 
 	assert.Equal(s.T(), s.state.AddParentWatcher(s.watcher), s.watcher)
 
@@ -370,11 +420,12 @@ func (s *ConnectionStateTestSuite) TestParentWatcher() {
 		State: zk.StateDisconnected,
 	}
 
-	time.Sleep(100 * time.Microsecond)
+	s.waitForEvents(2)
+
+	events := s.received()
 
-	assert.Equal(s.T(), 2, len(s.sessionEvents))
-	assert.Equal(s.T(), zk.StateConnecting, s.sessionEvents[0].State)
-	assert.Equal(s.T(), zk.StateDisconnected, s.sessionEvents[1].State)
+	assert.Equal(s.T(), zk.StateConnecting, events[0].State)
+	assert.Equal(s.T(), zk.StateDisconnected, events[1].State)
 }
 
 type ConnectionStateManagerTestSuite struct {
@@ -533,3 +584,85 @@ func (s *ConnectionStateManagerTestSuite) TestBlockUntilConnectedTimeouted() {
 
 	assert.Equal(s.T(), UNKNOWN, s.state.currentConnectionState)
 }
+
+func (s *ConnectionStateManagerTestSuite) TestBlockUntilConnectedWithContext() {
+	assert.NoError(s.T(), s.state.Start())
+
+	defer s.state.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	assert.Equal(s.T(), context.Canceled, s.state.BlockUntilConnectedWithContext(ctx))
+
+	s.state.AddStateChange(CONNECTED)
+
+	assert.NoError(s.T(), s.state.BlockUntilConnectedWithContext(context.Background()))
+}
+
+func (s *ConnectionStateManagerTestSuite) TestEmulateSessionExpired() {
+	var expired AtomicBool
+
+	s.state.SessionExpirationPercent = 50
+	s.state.sessionTimeout = func() time.Duration { return 200 * time.Millisecond }
+	s.state.expireSession = func() { expired.Set(true) }
+
+	assert.NoError(s.T(), s.state.Start())
+
+	defer s.state.Close()
+
+	assert.True(s.T(), s.state.AddStateChange(CONNECTED))
+	assert.True(s.T(), s.state.SetToSuspended())
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(s.T(), LOST, s.state.currentConnectionState)
+	assert.Equal(s.T(), []ConnectionState{CONNECTED, SUSPENDED, LOST}, s.receivedStates)
+	assert.True(s.T(), expired.Load(), "should expire the session")
+
+	expired.Set(false)
+
+	// reconnected before the session expiration
+	assert.True(s.T(), s.state.AddStateChange(RECONNECTED))
+	assert.True(s.T(), s.state.SetToSuspended())
+	assert.True(s.T(), s.state.AddStateChange(RECONNECTED))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(s.T(), RECONNECTED, s.state.currentConnectionState)
+	assert.Equal(s.T(), []ConnectionState{CONNECTED, SUSPENDED, LOST, RECONNECTED, SUSPENDED, RECONNECTED}, s.receivedStates)
+	assert.False(s.T(), expired.Load())
+}
+
+func (s *ConnectionStateManagerTestSuite) TestDisableSessionExpiration() {
+	s.state.SessionExpirationPercent = -1
+	s.state.sessionTimeout = func() time.Duration { return 100 * time.Millisecond }
+
+	assert.NoError(s.T(), s.state.Start())
+
+	defer s.state.Close()
+
+	assert.True(s.T(), s.state.SetToSuspended())
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(s.T(), SUSPENDED, s.state.currentConnectionState)
+	assert.Equal(s.T(), []ConnectionState{SUSPENDED}, s.receivedStates)
+}
+
+func TestConnectionStateErrorPolicy(t *testing.T) {
+	standard := NewStandardConnectionStateErrorPolicy()
+	session := NewSessionConnectionStateErrorPolicy()
+
+	for _, state := range []ConnectionState{CONNECTED, RECONNECTED, READ_ONLY} {
+		assert.False(t, standard.IsErrorState(state))
+		assert.False(t, session.IsErrorState(state))
+	}
+
+	assert.True(t, standard.IsErrorState(SUSPENDED))
+	assert.True(t, standard.IsErrorState(LOST))
+
+	assert.False(t, session.IsErrorState(SUSPENDED))
+	assert.True(t, session.IsErrorState(LOST))
+}
@@ -1,7 +1,12 @@
 package curator
 
+import (
+	"context"
+)
+
 type syncBuilder struct {
 	client        *curatorFramework
+	ctx           context.Context
 	backgrounding backgrounding
 }
 
@@ -9,7 +14,9 @@ func (b *syncBuilder) ForPath(givenPath string) (string, error) {
 	adjustedPath := b.client.fixForNamespace(givenPath, false)
 
 	if b.backgrounding.inBackground {
-		go b.pathInBackground(adjustedPath, givenPath)
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, SYNC, givenPath); err != nil {
+			return "", err
+		}
 
 		return givenPath, nil
 	} else {
@@ -38,14 +45,14 @@ func (b *syncBuilder) pathInBackground(path string, givenPath string) {
 
 		event.name = GetNodeFromPath(event.path)
 
-		b.backgrounding.callback(b.client, event)
+		b.client.callBackground(b.backgrounding.callback, event)
 	}
 }
 
 func (b *syncBuilder) pathInForeground(path string) (string, error) {
 	zkClient := b.client.ZookeeperClient()
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "sync", Name: "syncBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
@@ -58,6 +65,12 @@ func (b *syncBuilder) pathInForeground(path string) (string, error) {
 	return b.client.unfixForNamespace(syncPath), err
 }
 
+func (b *syncBuilder) WithContext(ctx context.Context) SyncBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
 func (b *syncBuilder) InBackground() SyncBuilder {
 	b.backgrounding = backgrounding{inBackground: true}
 
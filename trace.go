@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"context"
+	"strings"
 	"sync"
 	"time"
 )
@@ -77,3 +79,99 @@ func (t *timeTracer) Commit() {
 func (t *timeTracer) CommitAt(tm time.Time) {
 	t.driver.AddTime(t.name, tm.Sub(t.startTime))
 }
+
+// The trace of an operation performed on ZooKeeper
+type OperationTrace struct {
+	Context       context.Context // the context of the operation, which may carry the parent span
+	Op            string          // the operation, such as "getData" or "transaction"
+	Name          string          // the name of the traced code, such as "getDataBuilder.pathInForeground"
+	Path          string          // the path of the operation, if any
+	RequestBytes  int             // the size of the payload sent to the server
+	ResponseBytes int             // the size of the payload received from the server
+	StartTime     time.Time       // the time when the operation started
+	Latency       time.Duration   // the time elapsed until the operation completed, including the retries
+	SessionId     int64           // the session id of the connection, or 0 if not connected
+	Err           error           // the error returned by the operation
+	RetryCount    int             // the number of the retries
+}
+
+// Return the operation of the trace, or parse it from the name if not set, such as "getData" for "getDataBuilder.pathInForeground"
+func (t *OperationTrace) Operation() string {
+	if len(t.Op) > 0 {
+		return t.Op
+	}
+
+	if i := strings.IndexByte(t.Name, '.'); i > 0 && strings.HasSuffix(t.Name[:i], "Builder") {
+		return strings.TrimSuffix(t.Name[:i], "Builder")
+	}
+
+	return t.Name
+}
+
+// The trace of an event delivered to the watchers or the connection state listeners
+type EventTrace struct {
+	Name      string    // the kind of the event, "watched-event" or "connection-state-change"
+	Path      string    // the path of the watched event, if any
+	EventType string    // the type of the watched event
+	State     string    // the state of the session or the connection
+	SessionId int64     // the session id of the connection, or 0 if not connected
+	Time      time.Time // the time when the event was received
+}
+
+// Mechanism for recording the detailed traces of the operations and the events
+type AdvancedTracerDriver interface {
+	TracerDriver
+
+	// Record the trace of a completed operation
+	AddTrace(trace *OperationTrace)
+
+	// Record the trace of an event
+	AddEventTrace(trace *EventTrace)
+}
+
+type tracerDriverAdapter struct {
+	TracerDriver
+}
+
+// Adapt the TracerDriver to record the latency of the operations and count the events
+func NewAdvancedTracerDriverAdapter(driver TracerDriver) AdvancedTracerDriver {
+	if d, ok := driver.(AdvancedTracerDriver); ok {
+		return d
+	}
+
+	return &tracerDriverAdapter{driver}
+}
+
+func (d *tracerDriverAdapter) AddTrace(trace *OperationTrace) {
+	d.AddTime(trace.Name, trace.Latency)
+
+	if trace.Err != nil {
+		d.AddCount(trace.Name+"-errors", 1)
+	}
+}
+
+func (d *tracerDriverAdapter) AddEventTrace(trace *EventTrace) {
+	d.AddCount(trace.Name, 1)
+}
+
+// Return the size of the payload returned by an operation
+func responseSize(result interface{}) int {
+	switch v := result.(type) {
+	case *attemptResult:
+		return responseSize(v.value)
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	case []string:
+		n := 0
+
+		for _, s := range v {
+			n += len(s)
+		}
+
+		return n
+	}
+
+	return 0
+}
@@ -1,10 +1,13 @@
 package curator
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +42,154 @@ func TestTimeTrace(t *testing.T) {
 
 	d.AssertExpectations(t)
 }
+
+type recordingTracerDriver struct {
+	mockTracerDriver
+
+	lock   sync.Mutex
+	traces []*OperationTrace
+	events []*EventTrace
+}
+
+func (d *recordingTracerDriver) AddTrace(trace *OperationTrace) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.traces = append(d.traces, trace)
+}
+
+func (d *recordingTracerDriver) AddEventTrace(trace *EventTrace) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.events = append(d.events, trace)
+}
+
+func TestAdvancedTracerDriverAdapter(t *testing.T) {
+	d := &mockTracerDriver{}
+
+	d.On("AddTime", "op", time.Second*5).Return().Twice()
+	d.On("AddCount", "op-errors", 1).Return().Once()
+	d.On("AddCount", "watched-event", 1).Return().Once()
+
+	adapter := NewAdvancedTracerDriverAdapter(d)
+
+	adapter.AddTrace(&OperationTrace{Name: "op", Latency: time.Second * 5})
+	adapter.AddTrace(&OperationTrace{Name: "op", Latency: time.Second * 5, Err: errors.New("test")})
+	adapter.AddEventTrace(&EventTrace{Name: "watched-event"})
+
+	d.AssertExpectations(t)
+
+	// an advanced driver is used as is
+	r := &recordingTracerDriver{}
+
+	assert.Equal(t, r, NewAdvancedTracerDriverAdapter(r))
+}
+
+func TestOperationTrace(t *testing.T) {
+	assert.Equal(t, "getData", (&OperationTrace{Name: "getDataBuilder.pathInForeground"}).Operation())
+	assert.Equal(t, "checkExists", (&OperationTrace{Name: "checkExistsBuilder.pathInForeground"}).Operation())
+	assert.Equal(t, "curatorTransaction.commit", (&OperationTrace{Name: "curatorTransaction.commit"}).Operation())
+	assert.Equal(t, "retry-loop", (&OperationTrace{Name: "retry-loop"}).Operation())
+	assert.Equal(t, "transaction", (&OperationTrace{Op: "transaction", Name: "curatorTransaction.commit"}).Operation())
+}
+
+func TestRetryLoopTrace(t *testing.T) {
+	d := &recordingTracerDriver{}
+	sleeper := &mockRetrySleeper{}
+
+	retryLoop := newRetryLoop(NewRetryNTimes(3, time.Second), d)
+	retryLoop.retrySleeper = sleeper
+	retryLoop.trace = &OperationTrace{Name: "test", Path: "/node", RequestBytes: 4}
+	retryLoop.sessionId = func() int64 { return 123 }
+
+	sleeper.On("SleepFor", time.Second).Return(nil).Once()
+	d.On("AddCount", "retries-allowed", 1).Return().Once()
+
+	errs := []error{zk.ErrSessionExpired, nil}
+
+	_, err := retryLoop.CallWithRetry(func() (interface{}, error) {
+		return []byte("data"), errs[retryLoop.retryCount]
+	})
+
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 1, len(d.traces)) {
+		trace := d.traces[0]
+
+		assert.Equal(t, "test", trace.Name)
+		assert.Equal(t, "/node", trace.Path)
+		assert.Equal(t, 4, trace.RequestBytes)
+		assert.Equal(t, 4, trace.ResponseBytes)
+		assert.Equal(t, int64(123), trace.SessionId)
+		assert.Equal(t, 1, trace.RetryCount)
+		assert.NoError(t, trace.Err)
+	}
+
+	sleeper.AssertExpectations(t)
+	d.AssertExpectations(t)
+}
+
+func TestBuilderTrace(t *testing.T) {
+	d := &recordingTracerDriver{}
+
+	newMockContainer().Prepare(func(builder *CuratorFrameworkBuilder) {
+		builder.TracerDriver = d
+	}).Test(t, func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+		conn.On("Set", "/node", data, AnyVersion).Return(nil, zk.ErrNoNode).Once()
+
+		_, err := client.GetData().ForPath("/node")
+
+		assert.NoError(t, err)
+
+		_, err = client.SetData().ForPathWithData("/node", data)
+
+		assert.Equal(t, zk.ErrNoNode, err)
+
+		if assert.Equal(t, 2, len(d.traces)) {
+			assert.Equal(t, "getDataBuilder.pathInForeground", d.traces[0].Name)
+			assert.Equal(t, "/node", d.traces[0].Path)
+			assert.Equal(t, len(data), d.traces[0].ResponseBytes)
+			assert.NoError(t, d.traces[0].Err)
+
+			assert.Equal(t, "setDataBuilder.pathInForeground", d.traces[1].Name)
+			assert.Equal(t, len(data), d.traces[1].RequestBytes)
+			assert.Equal(t, zk.ErrNoNode, d.traces[1].Err)
+		}
+	})
+}
+
+// The driver which only records the names of the times
+type timesTracerDriver struct {
+	lock  sync.Mutex
+	names []string
+}
+
+func (d *timesTracerDriver) AddTime(name string, t time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.names = append(d.names, name)
+}
+
+func (d *timesTracerDriver) AddCount(name string, increment int) {}
+
+func TestBuilderTraceAdapter(t *testing.T) {
+	d := &timesTracerDriver{}
+
+	newMockContainer().Prepare(func(builder *CuratorFrameworkBuilder) {
+		builder.TracerDriver = d
+	}).Test(t, func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(data, stat, nil).Once()
+
+		_, err := client.GetData().ForPath("/node")
+
+		assert.NoError(t, err)
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		assert.Contains(t, d.names, "getDataBuilder.pathInForeground")
+	})
+}
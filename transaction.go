@@ -1,6 +1,11 @@
 package curator
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/samuel/go-zookeeper/zk"
 )
 
@@ -45,12 +50,44 @@ const (
 	OP_CHECK
 )
 
+var operationTypeNames = []string{"CREATE", "DELETE", "SET_DATA", "CHECK"}
+
+func (t OperationType) String() string {
+	if int(t) < len(operationTypeNames) {
+		return operationTypeNames[t]
+	}
+
+	return fmt.Sprintf("OperationType(%d)", int(t))
+}
+
+// The transactional operation to create a TTL node, which requires ExtendedZookeeperConnection
+type CreateTTLRequest struct {
+	Path  string
+	Data  []byte
+	Acl   []zk.ACL
+	Flags int32
+	TTL   time.Duration
+}
+
 // Holds the result of one transactional operation
 type TransactionResult struct {
 	Type       OperationType
 	ForPath    string
 	ResultPath string
 	ResultStat *zk.Stat
+	Err        error // the error of the operation if the transaction failed
+}
+
+// The error returned when one of the transactional operations failed and the transaction was rolled back
+type TransactionError struct {
+	Index int           // the index of the failed operation
+	Type  OperationType // the type of the failed operation
+	Path  string        // the path of the failed operation
+	Err   error         // the error returned by ZooKeeper
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction failed at operation #%d (%s %s): %s", e.Index, e.Type, e.Path, e.Err)
 }
 
 // Adds commit to the transaction interface
@@ -61,6 +98,9 @@ type TransactionFinal interface {
 	// One result is returned for each operation added.
 	// Further, the ordering of the results matches the ordering that the operations were added.
 	Commit() ([]TransactionResult, error)
+
+	// Commit all added operations as an atomic unit, aborting when the given context is done.
+	CommitWithContext(ctx context.Context) ([]TransactionResult, error)
 }
 
 // Syntactic sugar to make the fluent interface more readable
@@ -73,6 +113,9 @@ type TransactionBridge interface {
 type curatorTransaction struct {
 	client     *curatorFramework
 	operations []interface{}
+	parents    map[int]CreateMode // the mode of the missing parents should be created for the operation
+	protected  map[int]string     // the protected id of the create operation
+	err        error              // the first error when building the operations
 }
 
 func (t *curatorTransaction) Create() TransactionCreateBuilder {
@@ -91,60 +134,322 @@ func (t *curatorTransaction) Check() TransactionCheckBuilder {
 	return &transactionCheckBuilder{transaction: t, version: AnyVersion}
 }
 
+// Keep the first error when building the operations, which will be returned by Commit
+func (t *curatorTransaction) fail(err error) {
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
 func (t *curatorTransaction) And() TransactionFinal {
 	return t
 }
 
 func (t *curatorTransaction) Commit() ([]TransactionResult, error) {
+	return t.CommitWithContext(context.Background())
+}
+
+func (t *curatorTransaction) CommitWithContext(ctx context.Context) ([]TransactionResult, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
 	zkClient := t.client.ZookeeperClient()
+	firstTime := true
 
-	result, err := zkClient.NewRetryLoop().CallWithRetry(func() (interface{}, error) {
+	result, err := t.client.client.newTracedRetryLoop(&OperationTrace{Op: "transaction", Name: "curatorTransaction.commit"}).CallWithRetryContext(ctx, func() (interface{}, error) {
 		if conn, err := zkClient.Conn(); err != nil {
 			return nil, err
 		} else {
-			return conn.Multi(t.operations...)
+			if len(t.protected) > 0 && !firstTime {
+				// the previous attempt may have committed the transaction before the connection was lost
+				if attempt, err := t.findCommitted(conn); err != nil {
+					return nil, err
+				} else if attempt != nil {
+					return attempt, nil
+				}
+			}
+
+			firstTime = false
+
+			for {
+				operations, parents, err := t.prepareOperations(conn)
+
+				if err != nil {
+					return nil, err
+				}
+
+				responses, err := conn.Multi(operations...)
+
+				// try again if some missing parent was created by others
+				if !parentCreated(responses, parents) {
+					return &transactionAttempt{operations, parents, responses}, err
+				}
+			}
 		}
 	})
 
 	var results []TransactionResult
+	var parentErr *TransactionError
+	var operations []interface{}
+	var parents []bool
+	var responses []zk.MultiResponse
 
-	if responses, ok := result.([]zk.MultiResponse); ok {
-		for i, res := range responses {
-			switch req := t.operations[i].(type) {
-			case *zk.CreateRequest:
-				results = append(results, TransactionResult{
-					Type:       OP_CREATE,
-					ForPath:    req.Path,
-					ResultPath: t.client.unfixForNamespace(res.String),
-				})
-			case *zk.DeleteRequest:
-				results = append(results, TransactionResult{
-					Type:    OP_DELETE,
-					ForPath: req.Path,
-				})
-			case *zk.SetDataRequest:
-				results = append(results, TransactionResult{
-					Type:       OP_SET_DATA,
-					ForPath:    req.Path,
-					ResultStat: res.Stat,
-				})
-			case *zk.CheckVersionRequest:
-				results = append(results, TransactionResult{
-					Type:    OP_CHECK,
-					ForPath: req.Path,
-				})
+	if attempt, ok := result.(*transactionAttempt); ok {
+		operations, parents, responses = attempt.operations, attempt.parents, attempt.responses
+	}
+
+	for i, res := range responses {
+		if i >= len(operations) {
+			break
+		}
+
+		if parents[i] {
+			// the parent is created for the following operation
+			if parentErr == nil && res.Error != nil && res.Error != zk.ErrUnknown {
+				parentErr = &TransactionError{
+					Index: len(results),
+					Type:  OP_CREATE,
+					Path:  t.client.unfixForNamespace(operations[i].(*zk.CreateRequest).Path),
+					Err:   res.Error,
+				}
 			}
+
+			continue
 		}
+
+		r := TransactionResult{ResultStat: res.Stat, Err: res.Error}
+
+		switch req := operations[i].(type) {
+		case *zk.CreateRequest:
+			r.Type = OP_CREATE
+			r.ForPath = t.client.unfixForNamespace(req.Path)
+
+			if res.Error == nil {
+				r.ResultPath = t.client.unfixForNamespace(res.String)
+			}
+		case *CreateTTLRequest:
+			r.Type = OP_CREATE
+			r.ForPath = t.client.unfixForNamespace(req.Path)
+
+			if res.Error == nil {
+				r.ResultPath = t.client.unfixForNamespace(res.String)
+			}
+		case *zk.DeleteRequest:
+			r.Type = OP_DELETE
+			r.ForPath = t.client.unfixForNamespace(req.Path)
+		case *zk.SetDataRequest:
+			r.Type = OP_SET_DATA
+			r.ForPath = t.client.unfixForNamespace(req.Path)
+		case *zk.CheckVersionRequest:
+			r.Type = OP_CHECK
+			r.ForPath = t.client.unfixForNamespace(req.Path)
+		}
+
+		results = append(results, r)
+	}
+
+	if parentErr != nil {
+		return results, parentErr
+	} else if txErr := newTransactionError(results); txErr != nil {
+		return results, txErr
 	}
 
 	return results, err
 }
 
+// The operations prepared for an attempt to commit and their responses
+type transactionAttempt struct {
+	operations []interface{}
+	parents    []bool // the operation creates a missing parent
+	responses  []zk.MultiResponse
+}
+
+// Find the nodes of the protected create operations, the transaction has been committed if they exist.
+// Return the attempt with the paths of the created nodes, the stats of the operations are unknown.
+func (t *curatorTransaction) findCommitted(conn ZookeeperConnection) (*transactionAttempt, error) {
+	responses := make([]zk.MultiResponse, len(t.operations))
+
+	for i, op := range t.operations {
+		switch req := op.(type) {
+		case *zk.CreateRequest:
+			responses[i].String = req.Path
+		case *CreateTTLRequest:
+			responses[i].String = req.Path
+		}
+
+		if protectedId, ok := t.protected[i]; ok {
+			if createdPath, err := findProtectedNode(conn, responses[i].String, protectedId); err != nil {
+				return nil, err
+			} else if len(createdPath) == 0 {
+				return nil, nil
+			} else {
+				responses[i].String = createdPath
+			}
+		}
+	}
+
+	return &transactionAttempt{t.operations, make([]bool, len(t.operations)), responses}, nil
+}
+
+// Prepare the operations for the connection, the missing parents are inserted before the operations which create them,
+// and the container nodes fall back to the persistent nodes if the connection doesn't support them.
+func (t *curatorTransaction) prepareOperations(conn ZookeeperConnection) ([]interface{}, []bool, error) {
+	_, extended := conn.(ExtendedZookeeperConnection)
+
+	var operations []interface{}
+	var parents []bool
+
+	known := make(map[string]bool) // the nodes exist or will be created in the transaction
+
+	for i, op := range t.operations {
+		var path string
+
+		switch req := op.(type) {
+		case *zk.CreateRequest:
+			path = req.Path
+
+			if CreateMode(req.Flags).IsContainer() && !extended {
+				fallback := *req
+
+				fallback.Flags = int32(PERSISTENT)
+
+				op = &fallback
+			}
+		case *CreateTTLRequest:
+			path = req.Path
+
+			if !extended {
+				return nil, nil, ErrTTLNotSupported
+			}
+		}
+
+		if mode, ok := t.parents[i]; ok {
+			dirs, err := findMissingParents(conn, path, known)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if mode.IsContainer() && !extended {
+				mode = PERSISTENT
+			}
+
+			for _, dir := range dirs {
+				operations = append(operations, &zk.CreateRequest{
+					Path:  dir,
+					Data:  []byte{},
+					Acl:   getAclForDir(t.client.aclProvider, dir),
+					Flags: int32(mode),
+				})
+				parents = append(parents, true)
+
+				known[dir] = true
+			}
+		}
+
+		if len(path) > 0 {
+			known[path] = true
+		}
+
+		operations = append(operations, op)
+		parents = append(parents, false)
+	}
+
+	return operations, parents, nil
+}
+
+// Find the missing parents of the path, which don't exist and won't be created in the transaction
+func findMissingParents(conn ZookeeperConnection, path string, known map[string]bool) ([]string, error) {
+	var dirs []string
+
+	missing := false
+	pos := 1 // skip first slash, root is guaranteed to exist
+
+	for pos < len(path) {
+		idx := strings.Index(path[pos+1:], PATH_SEPARATOR)
+
+		if idx == -1 {
+			break
+		}
+
+		pos += idx + 1
+
+		subPath := path[:pos]
+
+		if known[subPath] {
+			continue
+		}
+
+		if !missing {
+			if exists, _, err := conn.Exists(subPath); err != nil {
+				return nil, err
+			} else if exists {
+				known[subPath] = true
+			} else {
+				missing = true
+			}
+		}
+
+		if missing {
+			dirs = append(dirs, subPath)
+		}
+	}
+
+	return dirs, nil
+}
+
+// Check whether some parent failed because it has been created by others
+func parentCreated(responses []zk.MultiResponse, parents []bool) bool {
+	for i, res := range responses {
+		if i < len(parents) && parents[i] && res.Error == zk.ErrNodeExists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Find the operation which caused the transaction to be rolled back,
+// the other operations are reported with a runtime inconsistency error (ErrUnknown).
+func newTransactionError(results []TransactionResult) *TransactionError {
+	failed := -1
+
+	for i, r := range results {
+		if r.Err == nil {
+			continue
+		}
+
+		if r.Err != zk.ErrUnknown {
+			failed = i
+
+			break
+		} else if failed < 0 {
+			failed = i
+		}
+	}
+
+	if failed < 0 {
+		return nil
+	}
+
+	return &TransactionError{
+		Index: failed,
+		Type:  results[failed].Type,
+		Path:  results[failed].ForPath,
+		Err:   results[failed].Err,
+	}
+}
+
 type transactionCreateBuilder struct {
-	transaction *curatorTransaction
-	createMode  CreateMode
-	compress    bool
-	acling      acling
+	transaction               *curatorTransaction
+	createMode                CreateMode
+	createParentsIfNeeded     bool
+	createParentsAsContainers bool
+	ttl                       time.Duration
+	doProtected               bool
+	compress                  bool
+	acling                    acling
 }
 
 func (b *transactionCreateBuilder) ForPath(path string) TransactionBridge {
@@ -152,30 +457,101 @@ func (b *transactionCreateBuilder) ForPath(path string) TransactionBridge {
 }
 
 func (b *transactionCreateBuilder) ForPathWithData(path string, payload []byte) TransactionBridge {
-	var data []byte
+	data := payload
 
 	if b.compress {
-		data, _ = b.transaction.client.compressionProvider.Compress(path, payload)
-	} else {
-		data = payload
+		if compressed, err := b.transaction.client.compressionProvider.Compress(path, payload); err != nil {
+			b.transaction.fail(&TransactionError{Index: len(b.transaction.operations), Type: OP_CREATE, Path: path, Err: err})
+		} else {
+			data = compressed
+		}
 	}
 
-	b.transaction.operations = append(b.transaction.operations, &zk.CreateRequest{
-		Path:  b.transaction.client.fixForNamespace(path, false),
-		Data:  data,
-		Acl:   b.acling.getAclList(path),
-		Flags: int32(b.createMode),
-	})
+	if b.createMode.IsTTL() != (b.ttl > 0) {
+		b.transaction.fail(ErrInvalidTTL)
+	}
+
+	b.transaction.fail(b.transaction.client.schemaSet.ValidateCreate(path, b.createMode, payload))
+
+	adjustedPath := b.transaction.client.fixForNamespace(path, false)
+
+	if b.doProtected {
+		if id, err := newProtectedId(); err != nil {
+			b.transaction.fail(err)
+		} else {
+			if b.transaction.protected == nil {
+				b.transaction.protected = make(map[int]string)
+			}
+
+			b.transaction.protected[len(b.transaction.operations)] = id
+
+			adjustedPath = adjustPathForProtection(adjustedPath, id)
+		}
+	}
+
+	if b.createParentsIfNeeded {
+		if b.transaction.parents == nil {
+			b.transaction.parents = make(map[int]CreateMode)
+		}
+
+		if b.createParentsAsContainers {
+			b.transaction.parents[len(b.transaction.operations)] = CONTAINER
+		} else {
+			b.transaction.parents[len(b.transaction.operations)] = PERSISTENT
+		}
+	}
+
+	if b.createMode.IsTTL() {
+		b.transaction.operations = append(b.transaction.operations, &CreateTTLRequest{
+			Path:  adjustedPath,
+			Data:  data,
+			Acl:   b.acling.getAclList(path),
+			Flags: int32(b.createMode),
+			TTL:   b.ttl,
+		})
+	} else {
+		b.transaction.operations = append(b.transaction.operations, &zk.CreateRequest{
+			Path:  adjustedPath,
+			Data:  data,
+			Acl:   b.acling.getAclList(path),
+			Flags: int32(b.createMode),
+		})
+	}
 
 	return b.transaction
 }
 
+func (b *transactionCreateBuilder) CreatingParentsIfNeeded() TransactionCreateBuilder {
+	b.createParentsIfNeeded = true
+
+	return b
+}
+
+func (b *transactionCreateBuilder) CreatingParentContainersIfNeeded() TransactionCreateBuilder {
+	b.createParentsIfNeeded = true
+	b.createParentsAsContainers = true
+
+	return b
+}
+
+func (b *transactionCreateBuilder) WithProtection() TransactionCreateBuilder {
+	b.doProtected = true
+
+	return b
+}
+
 func (b *transactionCreateBuilder) WithMode(mode CreateMode) TransactionCreateBuilder {
 	b.createMode = mode
 
 	return b
 }
 
+func (b *transactionCreateBuilder) WithTTL(ttl time.Duration) TransactionCreateBuilder {
+	b.ttl = ttl
+
+	return b
+}
+
 func (b *transactionCreateBuilder) WithACL(acls ...zk.ACL) TransactionCreateBuilder {
 	b.acling.aclList = acls
 
@@ -194,6 +570,8 @@ type transactionDeleteBuilder struct {
 }
 
 func (b *transactionDeleteBuilder) ForPath(path string) TransactionBridge {
+	b.transaction.fail(b.transaction.client.schemaSet.ValidateDelete(path))
+
 	b.transaction.operations = append(b.transaction.operations, &zk.DeleteRequest{
 		Path:    b.transaction.client.fixForNamespace(path, false),
 		Version: b.version,
@@ -219,12 +597,16 @@ func (b *transactionSetDataBuilder) ForPath(path string) TransactionBridge {
 }
 
 func (b *transactionSetDataBuilder) ForPathWithData(path string, payload []byte) TransactionBridge {
-	var data []byte
+	b.transaction.fail(b.transaction.client.schemaSet.ValidateData(path, payload))
+
+	data := payload
 
 	if b.compress {
-		data, _ = b.transaction.client.compressionProvider.Compress(path, payload)
-	} else {
-		data = payload
+		if compressed, err := b.transaction.client.compressionProvider.Compress(path, payload); err != nil {
+			b.transaction.fail(&TransactionError{Index: len(b.transaction.operations), Type: OP_SET_DATA, Path: path, Err: err})
+		} else {
+			data = compressed
+		}
 	}
 
 	b.transaction.operations = append(b.transaction.operations, &zk.SetDataRequest{
@@ -1,7 +1,9 @@
 package curator
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
@@ -55,22 +57,206 @@ func TestTransaction(t *testing.T) {
 		assert.Equal(t, results, []TransactionResult{
 			{
 				Type:       OP_CREATE,
-				ForPath:    "/parent/node1",
+				ForPath:    "/node1",
 				ResultPath: "/node1",
 			},
 			{
 				Type:    OP_DELETE,
-				ForPath: "/parent/node2",
+				ForPath: "/node2",
 			},
 			{
 				Type:       OP_SET_DATA,
-				ForPath:    "/parent/node3",
+				ForPath:    "/node3",
 				ResultStat: &zk.Stat{},
 			},
 			{
 				Type:    OP_CHECK,
-				ForPath: "/parent/node4",
+				ForPath: "/node4",
 			},
 		})
 	})
 }
+
+func TestTransactionError(t *testing.T) {
+	newMockContainer().WithNamespace("parent").Test(t, func(client CuratorFramework, conn *mockConn, acls []zk.ACL) {
+		conn.On("Exists", "/parent").Return(true, nil, nil).Once()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{Error: zk.ErrUnknown},
+			{Error: zk.ErrBadVersion},
+			{Error: zk.ErrUnknown},
+		}, nil).Once()
+
+		results, err := client.InTransaction().
+			Create().WithACL(acls...).ForPath("/node1").
+			SetData().WithVersion(3).ForPathWithData("/node2", []byte("data")).
+			Check().ForPath("/node3").
+			Commit()
+
+		assert.Equal(t, &TransactionError{
+			Index: 1,
+			Type:  OP_SET_DATA,
+			Path:  "/node2",
+			Err:   zk.ErrBadVersion,
+		}, err)
+		assert.EqualError(t, err, "transaction failed at operation #1 (SET_DATA /node2): zk: version conflict")
+		assert.Equal(t, []TransactionResult{
+			{Type: OP_CREATE, ForPath: "/node1", Err: zk.ErrUnknown},
+			{Type: OP_SET_DATA, ForPath: "/node2", Err: zk.ErrBadVersion},
+			{Type: OP_CHECK, ForPath: "/node3", Err: zk.ErrUnknown},
+		}, results)
+	})
+}
+
+func TestTransactionCreatingParents(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, aclProvider *mockACLProvider, acls []zk.ACL) {
+		aclProvider.On("GetAclForPath", mock.Anything).Return(OPEN_ACL_UNSAFE)
+
+		conn.On("Exists", "/parent").Return(true, nil, nil).Twice()
+		conn.On("Exists", "/parent/child").Return(false, nil, nil).Once()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{Error: zk.ErrNodeExists},
+			{Error: zk.ErrUnknown},
+			{Error: zk.ErrUnknown},
+			{Error: zk.ErrUnknown},
+		}, nil).Once()
+		conn.On("Exists", "/parent/child").Return(true, nil, nil).Once()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{String: "/parent/child/node1"},
+			{String: "/parent/child/node2"},
+			{String: "/parent/child/grand"},
+		}, nil).Once()
+
+		results, err := client.InTransaction().
+			Create().CreatingParentsIfNeeded().WithACL(acls...).ForPath("/parent/child/node1").
+			Create().CreatingParentsIfNeeded().WithACL(acls...).ForPath("/parent/child/node2").
+			Create().CreatingParentsIfNeeded().WithACL(acls...).ForPath("/parent/child/grand").
+			Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			&zk.CreateRequest{Path: "/parent/child", Data: []byte{}, Acl: OPEN_ACL_UNSAFE, Flags: int32(PERSISTENT)},
+			&zk.CreateRequest{Path: "/parent/child/node1", Data: []byte("default"), Acl: acls},
+			&zk.CreateRequest{Path: "/parent/child/node2", Data: []byte("default"), Acl: acls},
+			&zk.CreateRequest{Path: "/parent/child/grand", Data: []byte("default"), Acl: acls},
+			&zk.CreateRequest{Path: "/parent/child/node1", Data: []byte("default"), Acl: acls},
+			&zk.CreateRequest{Path: "/parent/child/node2", Data: []byte("default"), Acl: acls},
+			&zk.CreateRequest{Path: "/parent/child/grand", Data: []byte("default"), Acl: acls},
+		}, conn.operations)
+		assert.Equal(t, []TransactionResult{
+			{Type: OP_CREATE, ForPath: "/parent/child/node1", ResultPath: "/parent/child/node1"},
+			{Type: OP_CREATE, ForPath: "/parent/child/node2", ResultPath: "/parent/child/node2"},
+			{Type: OP_CREATE, ForPath: "/parent/child/grand", ResultPath: "/parent/child/grand"},
+		}, results)
+	})
+}
+
+func TestTransactionMissingParents(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, aclProvider *mockACLProvider, acls []zk.ACL) {
+		aclProvider.On("GetAclForPath", mock.Anything).Return(OPEN_ACL_UNSAFE)
+
+		conn.On("Exists", "/parent").Return(false, nil, nil).Once()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{Error: zk.ErrNoAuth},
+			{Error: zk.ErrUnknown},
+			{Error: zk.ErrUnknown},
+		}, nil).Once()
+
+		results, err := client.InTransaction().
+			Create().CreatingParentContainersIfNeeded().WithACL(acls...).ForPath("/parent/child/node").
+			Commit()
+
+		assert.Equal(t, &TransactionError{Index: 0, Type: OP_CREATE, Path: "/parent", Err: zk.ErrNoAuth}, err)
+		assert.Equal(t, []interface{}{
+			&zk.CreateRequest{Path: "/parent", Data: []byte{}, Acl: OPEN_ACL_UNSAFE, Flags: int32(PERSISTENT)},
+			&zk.CreateRequest{Path: "/parent/child", Data: []byte{}, Acl: OPEN_ACL_UNSAFE, Flags: int32(PERSISTENT)},
+			&zk.CreateRequest{Path: "/parent/child/node", Data: []byte("default"), Acl: acls},
+		}, conn.operations)
+		assert.Equal(t, []TransactionResult{
+			{Type: OP_CREATE, ForPath: "/parent/child/node", Err: zk.ErrUnknown},
+		}, results)
+	})
+}
+
+func TestTransactionProtection(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, acls []zk.ACL) {
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{{String: "/parent/_c_guid-node0000000001"}}, nil).Once()
+
+		results, err := client.InTransaction().
+			Create().WithProtection().WithMode(PERSISTENT_SEQUENTIAL).WithACL(acls...).ForPath("/parent/node").
+			Commit()
+
+		assert.NoError(t, err)
+		assert.Len(t, conn.operations, 1)
+		assert.Regexp(t, "^/parent/_c_[0-9a-f-]{36}-node$", conn.operations[0].(*zk.CreateRequest).Path)
+		assert.Equal(t, "/parent/_c_guid-node0000000001", results[0].ResultPath)
+	})
+}
+
+func TestTransactionProtectionRetried(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy, acls []zk.ACL) {
+		var protectedNode string
+
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(true).Once()
+
+		children := conn.On("Children", "/parent").Return([]string{"other"}, nil, nil).Once()
+
+		conn.On("Multi", mock.Anything).Run(func(args mock.Arguments) {
+			// the transaction was committed, but the connection was lost before the response
+			protectedNode = GetNodeFromPath(conn.operations[0].(*zk.CreateRequest).Path) + "0000000001"
+
+			children.Return([]string{"other", protectedNode}, nil, nil)
+		}).Return(nil, zk.ErrConnectionClosed).Once()
+
+		results, err := client.InTransaction().
+			Create().WithProtection().WithMode(PERSISTENT_SEQUENTIAL).WithACL(acls...).ForPath("/parent/node").
+			SetData().ForPathWithData("/other", []byte("data")).
+			Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []TransactionResult{
+			{Type: OP_CREATE, ForPath: conn.operations[0].(*zk.CreateRequest).Path, ResultPath: "/parent/" + protectedNode},
+			{Type: OP_SET_DATA, ForPath: "/other"},
+		}, results)
+	})
+}
+
+func TestTransactionCompressionError(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, compress *mockCompressionProvider, acls []zk.ACL) {
+		compress.On("Compress", "/node", []byte("data")).Return(nil, errors.New("too large")).Once()
+
+		_, err := client.InTransaction().
+			Check().ForPath("/other").
+			Create().Compressed().WithACL(acls...).ForPathWithData("/node", []byte("data")).
+			Commit()
+
+		assert.Equal(t, &TransactionError{Index: 1, Type: OP_CREATE, Path: "/node", Err: errors.New("too large")}, err)
+		assert.Empty(t, conn.operations)
+	})
+}
+
+func TestTransactionTTL(t *testing.T) {
+	newMockContainer().Test(t, func(client CuratorFramework, conn *mockConn, acls []zk.ACL) {
+		_, err := client.InTransaction().
+			Create().WithMode(PERSISTENT_WITH_TTL).WithACL(acls...).ForPath("/node").
+			Commit()
+
+		assert.Equal(t, ErrInvalidTTL, err)
+
+		_, err = client.InTransaction().
+			Create().WithMode(PERSISTENT_WITH_TTL).WithTTL(time.Minute).WithACL(acls...).ForPath("/node").
+			Commit()
+
+		assert.Equal(t, ErrTTLNotSupported, err)
+
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{{String: "/node"}}, nil).Once()
+
+		_, err = client.InTransaction().
+			Create().WithMode(CONTAINER).WithACL(acls...).ForPath("/node").
+			Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			&zk.CreateRequest{Path: "/node", Data: []byte("default"), Acl: acls, Flags: int32(PERSISTENT)},
+		}, conn.operations)
+	})
+}
@@ -0,0 +1,146 @@
+package curator
+
+import (
+	"reflect"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// Read and write the node data as values of type T, which are converted through the serializer of the client.
+//
+// The data will be serialized before compressed, and decompressed before deserialized.
+// If T is a pointer type, e.g. a protobuf message, a new value will be allocated for each read.
+type Typed[T any] struct {
+	client     CuratorFramework
+	serializer Serializer
+	compress   bool
+}
+
+func NewTyped[T any](client CuratorFramework) *Typed[T] {
+	return &Typed[T]{client: client, serializer: client.Serializer()}
+}
+
+// Return a copy which uses the given serializer
+func (t *Typed[T]) WithSerializer(serializer Serializer) *Typed[T] {
+	typed := *t
+
+	typed.serializer = serializer
+
+	return &typed
+}
+
+// Return a copy which compresses the data using the compression provider of the client
+func (t *Typed[T]) Compressed() *Typed[T] {
+	typed := *t
+
+	typed.compress = true
+
+	return &typed
+}
+
+// Read the node data as a value
+func (t *Typed[T]) Get(path string) (T, *zk.Stat, error) {
+	return t.get(t.client.GetData(), path)
+}
+
+// Read the node data as a value, and leave a watch on the node
+func (t *Typed[T]) Watch(path string, watcher Watcher) (T, *zk.Stat, error) {
+	return t.get(t.client.GetData().UsingWatcher(watcher), path)
+}
+
+func (t *Typed[T]) get(builder GetDataBuilder, path string) (T, *zk.Stat, error) {
+	var stat zk.Stat
+
+	if t.compress {
+		builder = builder.Decompressed()
+	}
+
+	data, err := builder.StoringStatIn(&stat).ForPath(path)
+
+	if err != nil {
+		var zero T
+
+		return zero, nil, err
+	}
+
+	value, err := t.Deserialize(path, data)
+
+	if err != nil {
+		return value, nil, err
+	}
+
+	return value, &stat, nil
+}
+
+// Deserialize the data as a new value
+func (t *Typed[T]) Deserialize(path string, data []byte) (T, error) {
+	value := newValue[T]()
+
+	var target interface{} = &value
+
+	if isPointer[T]() {
+		target = value // deserialize into the new value instead of the pointer to it
+	}
+
+	if err := t.serializer.Deserialize(path, data, target); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return value, nil
+}
+
+// Return a zero value, or a pointer to a new zero value if T is a pointer type
+func newValue[T any]() T {
+	var value T
+
+	if isPointer[T]() {
+		value = reflect.New(reflect.TypeOf(&value).Elem().Elem()).Interface().(T)
+	}
+
+	return value
+}
+
+func isPointer[T any]() bool {
+	return reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Ptr
+}
+
+// Serialize the value as the node data
+func (t *Typed[T]) Serialize(path string, value T) ([]byte, error) {
+	return t.serializer.Serialize(path, value)
+}
+
+// Write the value to the node
+func (t *Typed[T]) Set(path string, value T) (*zk.Stat, error) {
+	data, err := t.Serialize(path, value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	builder := t.client.SetData()
+
+	if t.compress {
+		builder = builder.Compressed()
+	}
+
+	return builder.ForPathWithData(path, data)
+}
+
+// Create the node with the value
+func (t *Typed[T]) Create(path string, value T, mode CreateMode) (string, error) {
+	data, err := t.Serialize(path, value)
+
+	if err != nil {
+		return "", err
+	}
+
+	builder := t.client.Create().WithMode(mode)
+
+	if t.compress {
+		builder = builder.Compressed()
+	}
+
+	return builder.ForPathWithData(path, data)
+}
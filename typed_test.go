@@ -0,0 +1,75 @@
+package curator
+
+import (
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TypedTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestTyped(t *testing.T) {
+	suite.Run(t, new(TypedTestSuite))
+}
+
+func (s *TypedTestSuite) TestGet() {
+	s.With(func(client CuratorFramework, conn *mockConn, stat *zk.Stat) {
+		conn.On("Get", "/node").Return([]byte(`{"Name":"test","Count":123}`), stat, nil).Once()
+
+		value, nodeStat, err := NewTyped[testValue](client).Get("/node")
+
+		assert.Equal(s.T(), testValue{"test", 123}, value)
+		assert.Equal(s.T(), stat, nodeStat)
+		assert.NoError(s.T(), err)
+	})
+}
+
+func (s *TypedTestSuite) TestGetPointer() {
+	s.With(func(client CuratorFramework, conn *mockConn, stat *zk.Stat) {
+		conn.On("Get", "/node").Return([]byte("data"), stat, nil).Twice()
+
+		typed := NewTyped[*testMessage](client).WithSerializer(NewProtoSerializer())
+
+		msg, _, err := typed.Get("/node")
+
+		assert.Equal(s.T(), &testMessage{[]byte("data")}, msg)
+		assert.NoError(s.T(), err)
+
+		msg2, _, err := typed.Get("/node")
+
+		assert.NoError(s.T(), err)
+		assert.False(s.T(), msg == msg2, "should allocate a new value for each read")
+	})
+}
+
+func (s *TypedTestSuite) TestSetCompressed() {
+	s.With(func(client CuratorFramework, conn *mockConn, compress *mockCompressionProvider, stat *zk.Stat) {
+		compress.On("Compress", "/node", []byte("test")).Return([]byte("compressed(test)"), nil).Once()
+		conn.On("Set", "/node", []byte("compressed(test)"), AnyVersion).Return(stat, nil).Once()
+
+		nodeStat, err := NewTyped[string](client).WithSerializer(NewRawSerializer()).Compressed().Set("/node", "test")
+
+		assert.Equal(s.T(), stat, nodeStat)
+		assert.NoError(s.T(), err)
+	})
+}
+
+func (s *TypedTestSuite) TestCreate() {
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.Serialization("gob")
+	}, func(client CuratorFramework, conn *mockConn, aclProvider *mockACLProvider) {
+		data, _ := NewGobSerializer().Serialize("/node", &testValue{"test", 123})
+
+		aclProvider.On("GetAclForPath", "/node").Return(OPEN_ACL_UNSAFE).Once()
+		conn.On("Create", "/node", data, int32(EPHEMERAL), OPEN_ACL_UNSAFE).Return("/node", nil).Once()
+
+		path, err := NewTyped[*testValue](client).Create("/node", &testValue{"test", 123}, EPHEMERAL)
+
+		assert.Equal(s.T(), "/node", path)
+		assert.NoError(s.T(), err)
+	})
+}
@@ -0,0 +1,298 @@
+package curator
+
+import (
+	"context"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// Compute the new data of the node from the old data and stat, the stat is nil if the node doesn't exist
+type UpdateFunc func(old []byte, stat *zk.Stat) ([]byte, error)
+
+// Compute the new data of the nodes from the old data and stats,
+// return the new data of the nodes should be changed, the other nodes will be checked for their versions.
+type MultiUpdateFunc func(olds map[string][]byte, stats map[string]*zk.Stat) (map[string][]byte, error)
+
+// Holds the result of the update
+type UpdateResult struct {
+	Path     string
+	Data     []byte   // the data written to the node
+	Stat     *zk.Stat // the stat of the node after updated
+	Created  bool     // the node was created because it doesn't exist
+	Attempts int      // how many attempts were needed
+}
+
+type updateBuilder struct {
+	client          *curatorFramework
+	ctx             context.Context
+	createIfMissing bool
+	createMode      CreateMode
+	acls            []zk.ACL
+	createParents   bool
+	retryPolicy     RetryPolicy
+	compress        bool
+}
+
+func (b *updateBuilder) ForPath(path string, update UpdateFunc) (*UpdateResult, error) {
+	startTime := time.Now()
+
+	for retryCount := 0; ; retryCount++ {
+		result, conflict, err := b.tryUpdate(path, update)
+
+		result.Attempts = retryCount + 1
+
+		if err == nil || !conflict || !b.allowRetry(retryCount, startTime) {
+			return result, err
+		}
+	}
+}
+
+// Try to update the node once, return true if the write failed because of a version conflict
+func (b *updateBuilder) tryUpdate(path string, update UpdateFunc) (*UpdateResult, bool, error) {
+	result := &UpdateResult{Path: path}
+
+	old, stat, err := b.read(path)
+
+	if err != nil {
+		return result, false, err
+	}
+
+	data, err := update(old, stat)
+
+	if err != nil {
+		return result, false, err
+	}
+
+	result.Data = data
+
+	if stat == nil {
+		var newStat zk.Stat
+
+		builder := b.client.Create().WithMode(b.createMode).StoringStatIn(&newStat).WithContext(b.ctx)
+
+		if b.createParents {
+			builder = builder.CreatingParentsIfNeeded()
+		}
+
+		if len(b.acls) > 0 {
+			builder = builder.WithACL(b.acls...)
+		}
+
+		if b.compress {
+			builder = builder.Compressed()
+		}
+
+		if _, err := builder.ForPathWithData(path, data); err != nil {
+			return result, isVersionConflict(err), err
+		}
+
+		result.Stat = &newStat
+		result.Created = true
+	} else {
+		builder := b.client.SetData().WithVersion(stat.Version).WithContext(b.ctx)
+
+		if b.compress {
+			builder = builder.Compressed()
+		}
+
+		if result.Stat, err = builder.ForPathWithData(path, data); err != nil {
+			return result, isVersionConflict(err), err
+		}
+	}
+
+	return result, false, nil
+}
+
+func (b *updateBuilder) ForPaths(paths []string, update MultiUpdateFunc) ([]UpdateResult, error) {
+	startTime := time.Now()
+
+	for retryCount := 0; ; retryCount++ {
+		results, conflict, err := b.tryUpdateAll(paths, update)
+
+		for i := range results {
+			results[i].Attempts = retryCount + 1
+		}
+
+		if err == nil || !conflict || !b.allowRetry(retryCount, startTime) {
+			return results, err
+		}
+	}
+}
+
+// Try to update the nodes once, return true if the commit failed because of a version conflict
+func (b *updateBuilder) tryUpdateAll(paths []string, update MultiUpdateFunc) ([]UpdateResult, bool, error) {
+	results := make([]UpdateResult, len(paths))
+	olds := make(map[string][]byte)
+	stats := make(map[string]*zk.Stat)
+
+	for i, path := range paths {
+		results[i].Path = path
+
+		if old, stat, err := b.read(path); err != nil {
+			return results, false, err
+		} else {
+			olds[path] = old
+			stats[path] = stat
+		}
+	}
+
+	changes, err := update(olds, stats)
+
+	if err != nil {
+		return results, false, err
+	}
+
+	transaction := b.client.InTransaction()
+
+	var final TransactionFinal
+
+	for i, path := range paths {
+		data, changed := changes[path]
+		stat := stats[path]
+
+		switch {
+		case changed && stat == nil:
+			builder := transaction.Create().WithMode(b.createMode)
+
+			if b.createParents {
+				builder = builder.CreatingParentsIfNeeded()
+			}
+
+			if len(b.acls) > 0 {
+				builder = builder.WithACL(b.acls...)
+			}
+
+			if b.compress {
+				builder = builder.Compressed()
+			}
+
+			final = builder.ForPathWithData(path, data)
+
+			results[i].Data = data
+			results[i].Created = true
+		case changed:
+			builder := transaction.SetData()
+
+			if b.compress {
+				builder = builder.Compressed()
+			}
+
+			final = builder.WithVersion(stat.Version).ForPathWithData(path, data)
+
+			results[i].Data = data
+		case stat != nil:
+			builder := transaction.Check()
+
+			final = builder.WithVersion(stat.Version).ForPath(path)
+		}
+	}
+
+	if final == nil {
+		return results, false, nil
+	}
+
+	txResults, err := final.CommitWithContext(b.ctx)
+
+	for _, txResult := range txResults {
+		for i := range results {
+			if results[i].Path == txResult.ForPath {
+				results[i].Stat = txResult.ResultStat
+			}
+		}
+	}
+
+	if txErr, ok := err.(*TransactionError); ok {
+		return results, isVersionConflict(txErr.Err), txErr.Err
+	}
+
+	return results, false, err
+}
+
+// Read the data and stat of the node, the stat is nil if the node doesn't exist and should be created
+func (b *updateBuilder) read(path string) ([]byte, *zk.Stat, error) {
+	var stat zk.Stat
+
+	builder := b.client.GetData().StoringStatIn(&stat).WithContext(b.ctx)
+
+	if b.compress {
+		builder = builder.Decompressed()
+	}
+
+	data, err := builder.ForPath(path)
+
+	if err == zk.ErrNoNode && b.createIfMissing {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return data, &stat, nil
+}
+
+func (b *updateBuilder) allowRetry(retryCount int, startTime time.Time) bool {
+	retryPolicy := b.retryPolicy
+
+	if retryPolicy == nil {
+		retryPolicy = b.client.ZookeeperClient().RetryPolicy()
+	}
+
+	if retryPolicy == nil {
+		return false
+	}
+
+	ctx := b.ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return retryPolicy.AllowRetry(retryCount, time.Now().Sub(startTime), &contextRetrySleeper{ctx, DefaultRetrySleeper})
+}
+
+// The write failed because the node was changed, created or deleted by others after it was read
+func isVersionConflict(err error) bool {
+	return err == zk.ErrBadVersion || err == zk.ErrNodeExists || err == zk.ErrNoNode
+}
+
+func (b *updateBuilder) CreatingIfMissing() UpdateBuilder {
+	b.createIfMissing = true
+
+	return b
+}
+
+func (b *updateBuilder) WithMode(mode CreateMode) UpdateBuilder {
+	b.createMode = mode
+
+	return b
+}
+
+func (b *updateBuilder) WithACL(acls ...zk.ACL) UpdateBuilder {
+	b.acls = acls
+
+	return b
+}
+
+func (b *updateBuilder) CreatingParentsIfNeeded() UpdateBuilder {
+	b.createParents = true
+
+	return b
+}
+
+func (b *updateBuilder) WithRetryPolicy(retryPolicy RetryPolicy) UpdateBuilder {
+	b.retryPolicy = retryPolicy
+
+	return b
+}
+
+func (b *updateBuilder) Compressed() UpdateBuilder {
+	b.compress = true
+
+	return b
+}
+
+func (b *updateBuilder) WithContext(ctx context.Context) UpdateBuilder {
+	b.ctx = ctx
+
+	return b
+}
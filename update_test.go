@@ -0,0 +1,131 @@
+package curator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UpdateBuilderTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestUpdateBuilder(t *testing.T) {
+	suite.Run(t, new(UpdateBuilderTestSuite))
+}
+
+func (s *UpdateBuilderTestSuite) TestUpdate() {
+	s.With(func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy) {
+		conn.On("Get", "/node").Return([]byte("1"), &zk.Stat{Version: 1}, nil).Once()
+		conn.On("Set", "/node", []byte("1+1"), int32(1)).Return(nil, zk.ErrBadVersion).Once()
+		retryPolicy.On("AllowRetry", 0, mock.Anything, mock.Anything).Return(true).Once()
+		conn.On("Get", "/node").Return([]byte("2"), &zk.Stat{Version: 2}, nil).Once()
+		conn.On("Set", "/node", []byte("2+1"), int32(2)).Return(&zk.Stat{Version: 3}, nil).Once()
+
+		result, err := client.Update().ForPath("/node", func(old []byte, stat *zk.Stat) ([]byte, error) {
+			return append(old, "+1"...), nil
+		})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), &UpdateResult{
+			Path:     "/node",
+			Data:     []byte("2+1"),
+			Stat:     &zk.Stat{Version: 3},
+			Attempts: 2,
+		}, result)
+	})
+}
+
+func (s *UpdateBuilderTestSuite) TestGiveUp() {
+	s.With(func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy) {
+		conn.On("Get", "/node").Return([]byte("1"), &zk.Stat{Version: 1}, nil).Once()
+		conn.On("Set", "/node", []byte("1+1"), int32(1)).Return(nil, zk.ErrBadVersion).Once()
+
+		result, err := client.Update().WithRetryPolicy(NewRetryNTimes(0, 0)).ForPath("/node", func(old []byte, stat *zk.Stat) ([]byte, error) {
+			return append(old, "+1"...), nil
+		})
+
+		assert.Equal(s.T(), zk.ErrBadVersion, err)
+		assert.Equal(s.T(), 1, result.Attempts)
+
+		errAbort := errors.New("abort")
+
+		conn.On("Get", "/node").Return([]byte("1"), &zk.Stat{Version: 1}, nil).Once()
+
+		_, err = client.Update().ForPath("/node", func(old []byte, stat *zk.Stat) ([]byte, error) {
+			return nil, errAbort
+		})
+
+		assert.Equal(s.T(), errAbort, err)
+
+		// the missing node is not a conflict without CreatingIfMissing
+		conn.On("Get", "/node").Return(nil, nil, zk.ErrNoNode).Once()
+
+		result, err = client.Update().ForPath("/node", func(old []byte, stat *zk.Stat) ([]byte, error) {
+			return nil, errAbort
+		})
+
+		assert.Equal(s.T(), zk.ErrNoNode, err)
+		assert.Equal(s.T(), 1, result.Attempts)
+	})
+}
+
+func (s *UpdateBuilderTestSuite) TestCreatingIfMissing() {
+	s.With(func(client CuratorFramework, conn *mockConn, compress *mockCompressionProvider, aclProvider *mockACLProvider, stat *zk.Stat) {
+		conn.On("Get", "/node").Return(nil, nil, zk.ErrNoNode).Once()
+		compress.On("Compress", "/node", []byte("init")).Return([]byte("compressed(init)"), nil).Once()
+		aclProvider.On("GetAclForPath", "/node").Return(OPEN_ACL_UNSAFE).Once()
+		conn.On("Create", "/node", []byte("compressed(init)"), int32(PERSISTENT), OPEN_ACL_UNSAFE).Return("/node", nil).Once()
+		conn.On("Exists", "/node").Return(true, stat, nil).Once()
+
+		result, err := client.Update().CreatingIfMissing().Compressed().ForPath("/node", func(old []byte, stat *zk.Stat) ([]byte, error) {
+			assert.Nil(s.T(), old)
+			assert.Nil(s.T(), stat)
+
+			return []byte("init"), nil
+		})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), &UpdateResult{
+			Path:     "/node",
+			Data:     []byte("init"),
+			Stat:     stat,
+			Created:  true,
+			Attempts: 1,
+		}, result)
+	})
+}
+
+func (s *UpdateBuilderTestSuite) TestForPaths() {
+	s.With(func(client CuratorFramework, conn *mockConn, retryPolicy *mockRetryPolicy) {
+		conn.On("Get", "/from").Return([]byte("1"), &zk.Stat{Version: 1}, nil).Twice()
+		conn.On("Get", "/to").Return([]byte("2"), &zk.Stat{Version: 2}, nil).Twice()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{Error: zk.ErrUnknown},
+			{Error: zk.ErrBadVersion},
+		}, nil).Once()
+		retryPolicy.On("AllowRetry", 0, mock.Anything, mock.Anything).Return(true).Once()
+		conn.On("Multi", mock.Anything).Return([]zk.MultiResponse{
+			{Stat: &zk.Stat{Version: 2}},
+			{},
+		}, nil).Once()
+
+		results, err := client.Update().ForPaths([]string{"/from", "/to"}, func(olds map[string][]byte, stats map[string]*zk.Stat) (map[string][]byte, error) {
+			assert.Equal(s.T(), map[string][]byte{"/from": []byte("1"), "/to": []byte("2")}, olds)
+
+			return map[string][]byte{"/from": []byte("0")}, nil
+		})
+
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), []UpdateResult{
+			{Path: "/from", Data: []byte("0"), Stat: &zk.Stat{Version: 2}, Attempts: 2},
+			{Path: "/to", Attempts: 2},
+		}, results)
+		assert.Equal(s.T(), &zk.SetDataRequest{Path: "/from", Data: []byte("0"), Version: 1}, conn.operations[0])
+		assert.Equal(s.T(), &zk.CheckVersionRequest{Path: "/to", Version: 2}, conn.operations[1])
+	})
+}
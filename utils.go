@@ -1,7 +1,6 @@
 package curator
 
 import (
-	"log"
 	"sync/atomic"
 	"unsafe"
 )
@@ -15,14 +14,14 @@ type Closeable interface {
 func CloseQuietly(closeable Closeable) (err error) {
 	defer func() {
 		if v := recover(); v != nil {
-			log.Printf("panic when closing %s, %v", closeable, v)
+			DefaultLogger.Warn("Panic when closing", "closeable", closeable, "panic", v)
 
 			err, _ = v.(error)
 		}
 	}()
 
 	if err = closeable.Close(); err != nil {
-		log.Printf("fail to close %s, %s", closeable, err)
+		DefaultLogger.Warn("Fail to close", "closeable", closeable, LOG_KEY_ERROR, err)
 	}
 
 	return
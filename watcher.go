@@ -22,15 +22,63 @@ func (w *simpleWatcher) process(event *zk.Event) {
 	w.Func(event)
 }
 
+// The watcher registered through a namespace facade, which receives the paths without the namespace
+type namespaceWatcher struct {
+	watcher           Watcher
+	unfixForNamespace func(path string) string
+}
+
+func (w *namespaceWatcher) process(event *zk.Event) {
+	unfixed := *event
+
+	unfixed.Path = w.unfixForNamespace(event.Path)
+
+	w.watcher.process(&unfixed)
+}
+
+// The watcher which reports its panics instead of crashing the dispatching goroutine
+type guardedWatcher struct {
+	watcher Watcher
+	errors  *callbackErrorHandler
+}
+
+func (w *guardedWatcher) process(event *zk.Event) {
+	w.errors.call(CallbackError{Source: "Watcher", Operation: "process", Path: event.Path, EventType: event.Type.String()}, func() error {
+		w.watcher.process(event)
+
+		return nil
+	})
+}
+
+// Return the original watcher if it was wrapped for the namespace or the error handling
+func unwrapWatcher(watcher Watcher) Watcher {
+	for {
+		switch w := watcher.(type) {
+		case *namespaceWatcher:
+			watcher = w.watcher
+		case *guardedWatcher:
+			watcher = w.watcher
+		default:
+			return watcher
+		}
+	}
+}
+
 type Watchers struct {
 	lock     sync.Mutex
 	watchers []Watcher
+	executor Executor
 }
 
 func NewWatchers(watchers ...Watcher) *Watchers {
 	return &Watchers{watchers: watchers}
 }
 
+// Create the watchers which are called through the given executor
+func NewWatchersWithExecutor(executor Executor, watchers ...Watcher) *Watchers {
+	return &Watchers{watchers: watchers, executor: executor}
+}
+
 func (w *Watchers) Len() int { return len(w.watchers) }
 
 func (w *Watchers) Add(watcher Watcher) Watcher {
@@ -48,7 +96,7 @@ func (w *Watchers) Remove(watcher Watcher) Watcher {
 	defer w.lock.Unlock()
 
 	for i, v := range w.watchers {
-		if v == watcher {
+		if unwrapWatcher(v) == unwrapWatcher(watcher) {
 			w.watchers = append(w.watchers[:i], w.watchers[i+1:]...)
 
 			return watcher
@@ -58,14 +106,26 @@ func (w *Watchers) Remove(watcher Watcher) Watcher {
 	return nil
 }
 
+// Call the watchers in order, through the executor if it was set, otherwise in the current goroutine
 func (w *Watchers) Fire(event *zk.Event) {
-	for _, watcher := range w.watchers {
+	execute(w.executor, func() { w.fire(event) })
+}
+
+func (w *Watchers) fire(event *zk.Event) {
+	for _, watcher := range w.snapshot() {
 		if watcher != nil {
-			go watcher.process(event)
+			watcher.process(event)
 		}
 	}
 }
 
+func (w *Watchers) snapshot() []Watcher {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]Watcher(nil), w.watchers...)
+}
+
 func (w *Watchers) Watch(events <-chan zk.Event) {
 	for {
 		if event, ok := <-events; !ok {
@@ -75,3 +135,142 @@ func (w *Watchers) Watch(events <-chan zk.Event) {
 		}
 	}
 }
+
+// The type of the watches removed by RemoveWatchesBuilder
+type WatcherType int32
+
+const (
+	WATCHER_CHILDREN WatcherType = 1 // the watches set by GetChildren()
+	WATCHER_DATA     WatcherType = 2 // the watches set by GetData() or CheckExists()
+	WATCHER_ANY      WatcherType = 3 // any watches, including the persistent watches
+)
+
+type watchRegistration struct {
+	path        string
+	watcherType WatcherType
+	watcher     Watcher
+	done        chan struct{} // closed when the watch is removed, to stop forwarding its events
+}
+
+// The one-time watches set by the builders, which could be removed before triggered
+type watchRegistry struct {
+	lock          sync.Mutex
+	registrations []*watchRegistration
+	executor      Executor
+	trace         func(event *zk.Event)
+}
+
+func newWatchRegistry(executor Executor, trace func(event *zk.Event)) *watchRegistry {
+	return &watchRegistry{executor: executor, trace: trace}
+}
+
+// Register the watcher and deliver the events to it until the channel is closed or the watcher is removed
+func (r *watchRegistry) watch(path string, watcherType WatcherType, watcher Watcher, events <-chan zk.Event) {
+	registration := &watchRegistration{path: path, watcherType: watcherType, watcher: watcher, done: make(chan struct{})}
+
+	r.lock.Lock()
+
+	r.registrations = append(r.registrations, registration)
+
+	r.lock.Unlock()
+
+	go func() {
+		defer r.unregister(registration)
+
+		for {
+			select {
+			case <-registration.done:
+				return
+
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-registration.done:
+					return // removed while receiving the event
+				default:
+				}
+
+				if r.trace != nil {
+					r.trace(&event)
+				}
+
+				execute(r.executor, func() { watcher.process(&event) })
+			}
+		}
+	}()
+}
+
+// Return the number of the registered watches
+func (r *watchRegistry) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.registrations)
+}
+
+func (r *watchRegistry) unregister(registration *watchRegistration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, v := range r.registrations {
+		if v == registration {
+			r.registrations = append(r.registrations[:i], r.registrations[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// Remove the watches of the given type on the path, which match the watcher or any watcher if it is nil,
+// their events are no longer forwarded even if the server still keeps the watches.
+// Return the number of the removed watches.
+func (r *watchRegistry) remove(path string, watcherType WatcherType, watcher Watcher) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var remaining []*watchRegistration
+
+	for _, registration := range r.registrations {
+		if registration.path == path &&
+			(watcherType == WATCHER_ANY || watcherType == registration.watcherType) &&
+			(watcher == nil || unwrapWatcher(watcher) == unwrapWatcher(registration.watcher)) {
+			close(registration.done)
+		} else {
+			remaining = append(remaining, registration)
+		}
+	}
+
+	removed := len(r.registrations) - len(remaining)
+
+	r.registrations = remaining
+
+	return removed
+}
+
+// The mode of the persistent watch added by WatchesBuilder
+type AddWatchMode int32
+
+const (
+	PERSISTENT_WATCH           AddWatchMode = 0 // watch the data and children changes of the node
+	PERSISTENT_RECURSIVE_WATCH AddWatchMode = 1 // watch the data changes of the node and all its descendants
+)
+
+type persistentWatch struct {
+	path     string
+	mode     AddWatchMode
+	watchers *Watchers
+	removed  AtomicBool
+}
+
+// The persistent watches which should be re-registered after the session is re-established
+type persistentWatches struct {
+	lock    sync.Mutex
+	watches map[string]*persistentWatch
+}
+
+func newPersistentWatches() *persistentWatches {
+	return &persistentWatches{watches: make(map[string]*persistentWatch)}
+}
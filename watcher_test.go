@@ -66,3 +66,24 @@ func TestWatchers(t *testing.T) {
 	assert.Equal(t, 1, len(events[2]))
 	assert.Equal(t, &evt, events[0][1])
 }
+
+func TestWatchRegistryRemove(t *testing.T) {
+	registry := newWatchRegistry(nil, nil)
+	events := make(chan zk.Event)
+	goroutines := runtime.NumGoroutine()
+
+	registry.watch("/node", WATCHER_DATA, NewWatcher(func(event *zk.Event) {
+		assert.Fail(t, "unexpected event", "event: %v", event)
+	}), events)
+
+	assert.Equal(t, 1, registry.Len())
+	assert.Equal(t, 1, registry.remove("/node", WATCHER_ANY, nil))
+	assert.Equal(t, 0, registry.Len())
+
+	// the forwarding goroutine exits without waiting for the server to fire the watch
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.True(t, runtime.NumGoroutine() <= goroutines)
+}
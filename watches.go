@@ -0,0 +1,284 @@
+package curator
+
+import (
+	"context"
+	"sync"
+)
+
+type watchesBuilder struct {
+	client  *curatorFramework
+	ctx     context.Context
+	watcher Watcher
+}
+
+func (b *watchesBuilder) Add(givenPath string, mode AddWatchMode) error {
+	if err := b.client.schemaSet.ValidateWatch(givenPath); err != nil {
+		return err
+	}
+
+	adjustedPath := b.client.fixForNamespace(givenPath, false)
+
+	zkClient := b.client.ZookeeperClient()
+
+	_, err := b.client.client.newTracedRetryLoop(&OperationTrace{Op: "addWatch", Name: "addWatchBuilder.pathInForeground", Path: adjustedPath}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
+		if conn, err := zkClient.Conn(); err != nil {
+			return nil, err
+		} else {
+			return nil, b.client.client.state.addPersistentWatch(conn, adjustedPath, mode, b.watcher)
+		}
+	})
+
+	return err
+}
+
+func (b *watchesBuilder) Remove(watcher Watcher) RemoveWatchesBuilder {
+	return &removeWatchesBuilder{client: b.client, ctx: b.ctx, watcher: watcher, watcherType: WATCHER_ANY}
+}
+
+func (b *watchesBuilder) RemoveAll() RemoveWatchesBuilder {
+	return &removeWatchesBuilder{client: b.client, ctx: b.ctx, watcherType: WATCHER_ANY}
+}
+
+func (b *watchesBuilder) UsingWatcher(watcher Watcher) WatchesBuilder {
+	b.watcher = b.client.wrapWatcher(watcher)
+
+	return b
+}
+
+func (b *watchesBuilder) WithContext(ctx context.Context) WatchesBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
+type removeWatchesBuilder struct {
+	client        *curatorFramework
+	ctx           context.Context
+	backgrounding backgrounding
+	watcher       Watcher
+	watcherType   WatcherType
+	local         bool
+	guaranteed    bool
+}
+
+func (b *removeWatchesBuilder) ForPath(givenPath string) error {
+	adjustedPath := b.client.fixForNamespace(givenPath, false)
+
+	if b.backgrounding.inBackground {
+		if err := b.client.runInBackgroundWithCallback(func() { b.pathInBackground(adjustedPath, givenPath) }, b.backgrounding, REMOVE_WATCHES, givenPath); err != nil {
+			return err
+		}
+
+		return nil
+	} else {
+		return b.pathInForeground(adjustedPath)
+	}
+}
+
+func (b *removeWatchesBuilder) pathInBackground(path string, givenPath string) {
+	tracer := b.client.ZookeeperClient().StartTracer("removeWatchesBuilder.pathInBackground")
+
+	defer tracer.Commit()
+
+	err := b.pathInForeground(path)
+
+	if b.backgrounding.callback != nil {
+		event := &curatorEvent{
+			eventType: REMOVE_WATCHES,
+			err:       err,
+			path:      b.client.unfixForNamespace(path),
+			context:   b.backgrounding.context,
+		}
+
+		if err != nil {
+			event.path = givenPath
+		}
+
+		event.name = GetNodeFromPath(event.path)
+
+		b.client.callBackground(b.backgrounding.callback, event)
+	}
+}
+
+func (b *removeWatchesBuilder) pathInForeground(path string) error {
+	var removedOnServer bool
+	var err error
+
+	if !b.local {
+		zkClient := b.client.ZookeeperClient()
+
+		_, err = b.client.client.newTracedRetryLoop(&OperationTrace{Op: "removeWatches", Name: "removeWatchesBuilder.pathInForeground", Path: path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
+			if conn, err := zkClient.Conn(); err != nil {
+				return nil, err
+			} else {
+				removedOnServer, err = b.removeOnServer(conn, path)
+
+				return nil, err
+			}
+		})
+
+		if err != nil && b.guaranteed && isConnectionError(err) {
+			b.client.failedRemoveWatches.addFailedRemoveWatches(path, b)
+
+			removedOnServer = b.watcher == nil // will be removed after reconnected
+			err = nil
+		}
+	}
+
+	if err != nil && err != ErrNoWatcher {
+		return err
+	}
+
+	if removed := b.client.client.state.removeWatches(path, b.watcherType, b.watcher); removed == 0 && !removedOnServer {
+		return ErrNoWatcher
+	}
+
+	return nil
+}
+
+// Remove the watches on the server if supported, otherwise the watches will only be removed locally.
+// Return true if the watches have been removed on the server.
+func (b *removeWatchesBuilder) removeOnServer(conn ZookeeperConnection, path string) (bool, error) {
+	if watchesConn, ok := conn.(WatchesZookeeperConnection); !ok {
+		return false, nil
+	} else if b.watcher != nil {
+		// the server doesn't know the watcher, just check the watches exist before removing it locally
+		return false, watchesConn.CheckWatches(path, int32(b.watcherType))
+	} else {
+		err := watchesConn.RemoveWatches(path, int32(b.watcherType))
+
+		return err == nil, err
+	}
+}
+
+func (b *removeWatchesBuilder) OfType(watcherType WatcherType) RemoveWatchesBuilder {
+	b.watcherType = watcherType
+
+	return b
+}
+
+func (b *removeWatchesBuilder) Locally() RemoveWatchesBuilder {
+	b.local = true
+
+	return b
+}
+
+func (b *removeWatchesBuilder) Guaranteed() RemoveWatchesBuilder {
+	b.guaranteed = true
+
+	return b
+}
+
+func (b *removeWatchesBuilder) WithContext(ctx context.Context) RemoveWatchesBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
+func (b *removeWatchesBuilder) InBackground() RemoveWatchesBuilder {
+	b.backgrounding = backgrounding{inBackground: true}
+
+	return b
+}
+
+func (b *removeWatchesBuilder) InBackgroundWithContext(context interface{}) RemoveWatchesBuilder {
+	b.backgrounding = backgrounding{inBackground: true, context: context}
+
+	return b
+}
+
+func (b *removeWatchesBuilder) InBackgroundWithCallback(callback BackgroundCallback) RemoveWatchesBuilder {
+	b.backgrounding = backgrounding{inBackground: true, callback: callback}
+
+	return b
+}
+
+func (b *removeWatchesBuilder) InBackgroundWithCallbackAndContext(callback BackgroundCallback, context interface{}) RemoveWatchesBuilder {
+	b.backgrounding = backgrounding{inBackground: true, context: context, callback: callback}
+
+	return b
+}
+
+type failedRemoveWatches struct {
+	path        string
+	watcherType WatcherType
+	watcher     Watcher
+}
+
+// Keeps the failed guaranteed removals, and retries them on the server whenever the connection is (re)established
+type failedRemoveWatchesManager struct {
+	client    *curatorFramework
+	lock      sync.Mutex
+	pending   []failedRemoveWatches
+	retrying  AtomicBool
+	requested AtomicBool // another retry was requested while retrying
+}
+
+func newFailedRemoveWatchesManager(client *curatorFramework) *failedRemoveWatchesManager {
+	return &failedRemoveWatchesManager{client: client}
+}
+
+func (m *failedRemoveWatchesManager) addFailedRemoveWatches(path string, b *removeWatchesBuilder) {
+	m.lock.Lock()
+
+	m.pending = append(m.pending, failedRemoveWatches{path, b.watcherType, b.watcher})
+
+	m.lock.Unlock()
+}
+
+func (m *failedRemoveWatchesManager) StateChanged(client CuratorFramework, newState ConnectionState) {
+	if newState == CONNECTED || newState == RECONNECTED {
+		m.client.runInBackground(m.retryFailedRemoveWatches)
+	}
+}
+
+// Retry the pending removals, the requests while retrying are served by the running retry
+func (m *failedRemoveWatchesManager) retryFailedRemoveWatches() {
+	m.requested.Set(true)
+
+	for m.requested.Load() && m.retrying.CompareAndSwap(false, true) {
+		for m.requested.Swap(false) {
+			m.retryPendingRemoveWatches()
+		}
+
+		m.retrying.Set(false)
+	}
+}
+
+func (m *failedRemoveWatchesManager) retryPendingRemoveWatches() {
+	m.lock.Lock()
+
+	pending := m.pending
+
+	m.pending = nil
+
+	m.lock.Unlock()
+
+	for _, op := range pending {
+		b := &removeWatchesBuilder{
+			client:      m.client,
+			watcherType: op.watcherType,
+			watcher:     op.watcher,
+		}
+
+		zkClient := m.client.ZookeeperClient()
+
+		_, err := m.client.client.newTracedRetryLoop(&OperationTrace{Op: "removeWatches", Name: "failedRemoveWatchesManager.retryFailedRemoveWatches", Path: op.path}).CallWithRetryContext(b.ctx, func() (interface{}, error) {
+			if conn, err := zkClient.Conn(); err != nil {
+				return nil, err
+			} else {
+				_, err := b.removeOnServer(conn, op.path)
+
+				return nil, err
+			}
+		})
+
+		if err != nil && isConnectionError(err) {
+			m.lock.Lock()
+
+			m.pending = append(m.pending, op)
+
+			m.lock.Unlock()
+		}
+	}
+}
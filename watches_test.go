@@ -0,0 +1,214 @@
+package curator
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WatchesBuilderTestSuite struct {
+	mockContainerTestSuite
+}
+
+func TestWatchesBuilder(t *testing.T) {
+	suite.Run(t, new(WatchesBuilderTestSuite))
+}
+
+func (s *WatchesBuilderTestSuite) TestNotSupported() {
+	s.With(func(client CuratorFramework, conn *mockConn) {
+		assert.Equal(s.T(), ErrWatchesNotSupported, client.Watches().Add("/node", PERSISTENT_WATCH))
+		assert.Equal(s.T(), ErrNoWatcher, client.Watches().RemoveAll().ForPath("/node"))
+	})
+}
+
+func (s *WatchesBuilderTestSuite) TestAdd() {
+	conn := &mockExtendedConn{mockConn{log: s.T().Logf}}
+	dialer := &mockZookeeperDialer{log: s.T().Logf}
+	events := make(chan zk.Event)
+
+	dialer.On("Dial", "connStr", DEFAULT_SESSION_TIMEOUT, false).Return(conn, nil, nil).Once()
+	conn.On("Exists", "/parent").Return(true, nil, nil).Once()
+	conn.On("AddWatch", "/parent/child", int32(PERSISTENT_RECURSIVE_WATCH)).Return(events, nil).Once()
+	conn.On("Close").Return().Once()
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.ZookeeperDialer = dialer
+		builder.Namespace = "parent"
+	}, func(client CuratorFramework, wg *sync.WaitGroup) {
+		wg.Add(1)
+
+		client.CuratorListenable().AddListener(NewCuratorListener(func(client CuratorFramework, event CuratorEvent) error {
+			if event.Type() == WATCHED {
+				assert.Equal(s.T(), "/child/node", event.Path())
+
+				wg.Done()
+			}
+
+			return nil
+		}))
+
+		watcher := NewWatcher(func(event *zk.Event) {
+			assert.Equal(s.T(), zk.EventNodeDataChanged, event.Type)
+			assert.Equal(s.T(), "/child/node", event.Path)
+
+			wg.Done()
+		})
+
+		assert.NoError(s.T(), client.Watches().UsingWatcher(watcher).Add("/child", PERSISTENT_RECURSIVE_WATCH))
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/parent/child/node"}
+	})
+
+	dialer.AssertExpectations(s.T())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *WatchesBuilderTestSuite) TestReregister() {
+	conn := &mockExtendedConn{mockConn{log: s.T().Logf}}
+	dialer := &mockZookeeperDialer{log: s.T().Logf}
+	events := make(chan zk.Event)
+	newEvents := make(chan zk.Event)
+
+	dialer.On("Dial", "connStr", DEFAULT_SESSION_TIMEOUT, false).Return(conn, nil, nil).Twice()
+	conn.On("AddWatch", "/node", int32(PERSISTENT_WATCH)).Return(events, nil).Once()
+	conn.On("AddWatch", "/node", int32(PERSISTENT_WATCH)).Return(newEvents, nil).Once()
+	conn.On("Close").Return().Twice()
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.ZookeeperDialer = dialer
+		builder.EnsembleProvider = NewFixedEnsembleProvider("connStr")
+	}, func(client CuratorFramework, wg *sync.WaitGroup) {
+		watcher := NewWatcher(func(event *zk.Event) {
+			assert.Equal(s.T(), "/node", event.Path)
+
+			wg.Done()
+		})
+
+		assert.NoError(s.T(), client.Watches().UsingWatcher(watcher).Add("/node", PERSISTENT_WATCH))
+
+		assert.NoError(s.T(), client.(*curatorFramework).client.state.reset())
+
+		newEvents <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/node"}
+	})
+
+	dialer.AssertExpectations(s.T())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *WatchesBuilderTestSuite) TestRemove() {
+	conn := &mockExtendedConn{mockConn{log: s.T().Logf}}
+	dialer := &mockZookeeperDialer{log: s.T().Logf}
+	events := make(chan zk.Event, 1) // not received after the watcher is removed
+
+	dialer.On("Dial", "connStr", DEFAULT_SESSION_TIMEOUT, false).Return(conn, nil, nil).Once()
+	conn.On("AddWatch", "/node", int32(PERSISTENT_WATCH)).Return(events, nil).Once()
+	conn.On("CheckWatches", "/node", int32(WATCHER_ANY)).Return(nil).Twice()
+	conn.On("RemoveWatches", "/node", int32(WATCHER_ANY)).Return(nil).Once()
+	conn.On("Close").Return().Once()
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.ZookeeperDialer = dialer
+	}, func(client CuratorFramework) {
+		watcher := NewWatcher(func(event *zk.Event) {
+			assert.Fail(s.T(), "unexpected event", "event: %v", event)
+		})
+
+		assert.NoError(s.T(), client.Watches().UsingWatcher(watcher).Add("/node", PERSISTENT_WATCH))
+		assert.NoError(s.T(), client.Watches().Remove(watcher).ForPath("/node"))
+		assert.Equal(s.T(), ErrNoWatcher, client.Watches().Remove(watcher).ForPath("/node"))
+		assert.NoError(s.T(), client.Watches().RemoveAll().ForPath("/node"))
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/node"}
+	})
+
+	dialer.AssertExpectations(s.T())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *WatchesBuilderTestSuite) TestRemoveLocally() {
+	s.With(func(client CuratorFramework, conn *mockConn, data []byte, stat *zk.Stat) {
+		events := make(chan zk.Event, 1) // not received after the watcher is removed
+
+		conn.On("GetW", "/node").Return(data, stat, events, nil).Once()
+
+		watcher := NewWatcher(func(event *zk.Event) {
+			assert.Fail(s.T(), "unexpected event", "event: %v", event)
+		})
+
+		_, err := client.GetData().UsingWatcher(watcher).ForPath("/node")
+
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), ErrNoWatcher, client.Watches().Remove(watcher).OfType(WATCHER_CHILDREN).Locally().ForPath("/node"))
+		assert.NoError(s.T(), client.Watches().Remove(watcher).OfType(WATCHER_DATA).Locally().ForPath("/node"))
+		assert.Equal(s.T(), ErrNoWatcher, client.Watches().Remove(watcher).ForPath("/node"))
+
+		events <- zk.Event{Type: zk.EventNodeDataChanged, State: zk.StateHasSession, Path: "/node"}
+	})
+}
+
+func (s *WatchesBuilderTestSuite) TestRemoveGuaranteed() {
+	conn := &mockExtendedConn{mockConn{log: s.T().Logf}}
+	dialer := &mockZookeeperDialer{log: s.T().Logf}
+
+	dialer.On("Dial", "connStr", DEFAULT_SESSION_TIMEOUT, false).Return(conn, nil, nil).Once()
+	conn.On("RemoveWatches", "/node", int32(WATCHER_CHILDREN)).Return(zk.ErrConnectionClosed).Once()
+	conn.On("Close").Return().Once()
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.ZookeeperDialer = dialer
+	}, func(client CuratorFramework, retryPolicy *mockRetryPolicy, wg *sync.WaitGroup) {
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+		assert.NoError(s.T(), client.Watches().RemoveAll().OfType(WATCHER_CHILDREN).Guaranteed().ForPath("/node"))
+
+		conn.On("RemoveWatches", "/node", int32(WATCHER_CHILDREN)).Return(nil).Run(func(args mock.Arguments) {
+			wg.Done()
+		}).Once()
+
+		client.(*curatorFramework).stateManager.AddStateChange(RECONNECTED)
+	})
+
+	dialer.AssertExpectations(s.T())
+	conn.AssertExpectations(s.T())
+}
+
+func (s *WatchesBuilderTestSuite) TestRemoveGuaranteedWhileRetrying() {
+	conn := &mockExtendedConn{mockConn{log: s.T().Logf}}
+	dialer := &mockZookeeperDialer{log: s.T().Logf}
+
+	dialer.On("Dial", "connStr", DEFAULT_SESSION_TIMEOUT, false).Return(conn, nil, nil).Once()
+	conn.On("RemoveWatches", "/node", int32(WATCHER_CHILDREN)).Return(zk.ErrConnectionClosed).Once()
+	conn.On("Close").Return().Once()
+
+	s.WithPrepare(func(builder *CuratorFrameworkBuilder) {
+		builder.ZookeeperDialer = dialer
+	}, func(client CuratorFramework, retryPolicy *mockRetryPolicy, wg *sync.WaitGroup) {
+		manager := client.(*curatorFramework).failedRemoveWatches
+
+		retryPolicy.On("AllowRetry", 0, mock.AnythingOfType("time.Duration"), mock.Anything).Return(false).Once()
+
+		assert.NoError(s.T(), client.Watches().RemoveAll().OfType(WATCHER_CHILDREN).Guaranteed().ForPath("/node"))
+
+		conn.On("RemoveWatches", "/node", int32(WATCHER_CHILDREN)).Return(nil).Run(func(args mock.Arguments) {
+			// another removal fails and asks for a retry while the pass is running
+			manager.lock.Lock()
+			manager.pending = append(manager.pending, failedRemoveWatches{path: "/other", watcherType: WATCHER_DATA})
+			manager.lock.Unlock()
+
+			manager.retryFailedRemoveWatches()
+		}).Once()
+		conn.On("RemoveWatches", "/other", int32(WATCHER_DATA)).Return(nil).Run(func(args mock.Arguments) {
+			wg.Done()
+		}).Once()
+
+		client.(*curatorFramework).stateManager.AddStateChange(RECONNECTED)
+	})
+
+	dialer.AssertExpectations(s.T())
+	conn.AssertExpectations(s.T())
+}
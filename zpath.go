@@ -0,0 +1,184 @@
+package curator
+
+import (
+	"fmt"
+	"strings"
+)
+
+type zpathNode struct {
+	name    string
+	isParam bool
+}
+
+// The path template of ZooKeeper nodes, the parameter components are surrounded by braces,
+// e.g. "/tenants/{tenant}/services/{service}/config"
+type ZPath struct {
+	template string
+	nodes    []zpathNode
+}
+
+// Parse the path template, which must be a valid path after the parameters are resolved
+func ParseZPath(template string) (*ZPath, error) {
+	if err := ValidatePath(template); err != nil {
+		return nil, err
+	}
+
+	p := &ZPath{template: template}
+
+	if template == PATH_SEPARATOR {
+		return p, nil
+	}
+
+	params := make(map[string]bool)
+
+	for _, part := range strings.Split(template[1:], PATH_SEPARATOR) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := part[1 : len(part)-1]
+
+			if len(name) == 0 || strings.ContainsAny(name, "{}") {
+				return nil, fmt.Errorf("invalid parameter %s in path template: %s", part, template)
+			} else if params[name] {
+				return nil, fmt.Errorf("duplicate parameter %s in path template: %s", part, template)
+			}
+
+			params[name] = true
+
+			p.nodes = append(p.nodes, zpathNode{name, true})
+		} else if strings.ContainsAny(part, "{}") {
+			return nil, fmt.Errorf("invalid path template: %s", template)
+		} else {
+			p.nodes = append(p.nodes, zpathNode{part, false})
+		}
+	}
+
+	return p, nil
+}
+
+// Parse the path template, panic if the template is invalid
+func MustParseZPath(template string) *ZPath {
+	p, err := ParseZPath(template)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func (p *ZPath) String() string {
+	return p.template
+}
+
+// Return the names of the parameters in order
+func (p *ZPath) Parameters() []string {
+	var params []string
+
+	for _, node := range p.nodes {
+		if node.isParam {
+			params = append(params, node.name)
+		}
+	}
+
+	return params
+}
+
+// Return true if the path has no parameters
+func (p *ZPath) IsResolved() bool {
+	for _, node := range p.nodes {
+		if node.isParam {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolve the parameters in order, and return the path
+func (p *ZPath) Resolve(params ...string) (string, error) {
+	parts := make([]string, 0, len(p.nodes))
+
+	for _, node := range p.nodes {
+		if !node.isParam {
+			parts = append(parts, node.name)
+		} else if len(params) == 0 {
+			return "", fmt.Errorf("missing parameter {%s} of path template: %s", node.name, p.template)
+		} else {
+			parts = append(parts, params[0])
+
+			params = params[1:]
+		}
+	}
+
+	if len(params) > 0 {
+		return "", fmt.Errorf("too many parameters for path template: %s", p.template)
+	}
+
+	return p.join(parts)
+}
+
+// Resolve the parameters by name, and return the path
+func (p *ZPath) ResolveMap(params map[string]string) (string, error) {
+	parts := make([]string, 0, len(p.nodes))
+
+	for _, node := range p.nodes {
+		if !node.isParam {
+			parts = append(parts, node.name)
+		} else if value, exists := params[node.name]; !exists {
+			return "", fmt.Errorf("missing parameter {%s} of path template: %s", node.name, p.template)
+		} else {
+			parts = append(parts, value)
+		}
+	}
+
+	return p.join(parts)
+}
+
+func (p *ZPath) join(parts []string) (string, error) {
+	for _, part := range parts {
+		if len(part) == 0 || strings.Contains(part, PATH_SEPARATOR) {
+			return "", fmt.Errorf("invalid parameter value \"%s\" for path template: %s", part, p.template)
+		}
+	}
+
+	path := PATH_SEPARATOR + strings.Join(parts, PATH_SEPARATOR)
+
+	if err := ValidatePath(path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Match the path against the template, return the parameters if matched,
+// which could be used to dispatch the watched events to the resolved nodes.
+func (p *ZPath) Match(path string) (map[string]string, bool) {
+	var parts []string
+
+	if path != PATH_SEPARATOR {
+		if !strings.HasPrefix(path, PATH_SEPARATOR) {
+			return nil, false
+		}
+
+		parts = strings.Split(path[1:], PATH_SEPARATOR)
+	}
+
+	if len(parts) != len(p.nodes) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+
+	for i, node := range p.nodes {
+		if node.isParam {
+			if len(parts[i]) == 0 {
+				return nil, false
+			}
+
+			params[node.name] = parts[i]
+		} else if node.name != parts[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
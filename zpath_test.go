@@ -0,0 +1,91 @@
+package curator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseZPath(t *testing.T) {
+	p, err := ParseZPath("/tenants/{tenant}/services/{service}/config")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/tenants/{tenant}/services/{service}/config", p.String())
+	assert.Equal(t, []string{"tenant", "service"}, p.Parameters())
+	assert.False(t, p.IsResolved())
+
+	p, err = ParseZPath("/")
+
+	assert.NoError(t, err)
+	assert.True(t, p.IsResolved())
+
+	for template, msg := range map[string]string{
+		"tenants":          "Path must start with / character",
+		"/tenants/":        "Path must not end with / character",
+		"/tenants/{}":      "invalid parameter {} in path template: /tenants/{}",
+		"/a/{id}/b/{id}":   "duplicate parameter {id} in path template: /a/{id}/b/{id}",
+		"/tenants/x{id}":   "invalid path template: /tenants/x{id}",
+		"/tenants//{id}":   "empty node name specified @ 9",
+		"/tenants/{a}{b}}": "invalid parameter {a}{b}} in path template: /tenants/{a}{b}}",
+	} {
+		_, err := ParseZPath(template)
+
+		assert.EqualError(t, err, msg, template)
+	}
+
+	assert.Panics(t, func() { MustParseZPath("invalid") })
+}
+
+func TestResolveZPath(t *testing.T) {
+	p := MustParseZPath("/tenants/{tenant}/services/{service}/config")
+
+	path, err := p.Resolve("acme", "web")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/tenants/acme/services/web/config", path)
+
+	path, err = p.ResolveMap(map[string]string{"tenant": "acme", "service": "web"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/tenants/acme/services/web/config", path)
+
+	_, err = p.Resolve("acme")
+
+	assert.EqualError(t, err, "missing parameter {service} of path template: /tenants/{tenant}/services/{service}/config")
+
+	_, err = p.Resolve("acme", "web", "db")
+
+	assert.EqualError(t, err, "too many parameters for path template: /tenants/{tenant}/services/{service}/config")
+
+	_, err = p.ResolveMap(map[string]string{"tenant": "acme"})
+
+	assert.EqualError(t, err, "missing parameter {service} of path template: /tenants/{tenant}/services/{service}/config")
+
+	_, err = p.Resolve("acme", "web/db")
+
+	assert.EqualError(t, err, "invalid parameter value \"web/db\" for path template: /tenants/{tenant}/services/{service}/config")
+
+	_, err = p.Resolve("acme", "..")
+
+	assert.EqualError(t, err, "relative paths not allowed @ 24")
+}
+
+func TestMatchZPath(t *testing.T) {
+	p := MustParseZPath("/tenants/{tenant}/services/{service}/config")
+
+	params, matched := p.Match("/tenants/acme/services/web/config")
+
+	assert.True(t, matched)
+	assert.Equal(t, map[string]string{"tenant": "acme", "service": "web"}, params)
+
+	for _, path := range []string{"/tenants/acme/services/web", "/tenants/acme/services/web/config/x", "/tenants/acme/hosts/web/config", "/tenants//services/web/config", "tenants"} {
+		_, matched := p.Match(path)
+
+		assert.False(t, matched, path)
+	}
+
+	params, matched = MustParseZPath("/").Match("/")
+
+	assert.True(t, matched)
+	assert.Empty(t, params)
+}